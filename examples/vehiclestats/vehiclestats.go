@@ -71,13 +71,13 @@ func run(args []string) error {
 	// https://pkg.go.dev/github.com/nugget/roadtrip-go/roadtrip#Vehicle
 	logger.Debug("Loaded vehicle", "vehicle", vehicle)
 
-	// TODO: Better interface for Vehicles[0] fields.
-	fmt.Printf("\n\n%s\n\n", vehicle.Vehicles[0].Name)
+	// https://pkg.go.dev/github.com/nugget/roadtrip-go/roadtrip#Vehicle.Info
+	info, err := vehicle.Info()
+	if err != nil {
+		return err
+	}
 
-	var (
-		totalFuelCost float64
-		totalUnits    float64
-	)
+	fmt.Printf("\n\n%s\n\n", info.Name)
 
 	// https://pkg.go.dev/github.com/nugget/roadtrip-go/roadtrip#FuelRecord
 	for i, f := range vehicle.FuelRecords {
@@ -85,28 +85,59 @@ func run(args []string) error {
 			"index", i,
 			"fuel", f,
 		)
-
-		totalFuelCost += f.TotalPrice
-		totalUnits += f.FillAmount
 	}
 
 	startOdometer := vehicle.FuelRecords[0].Odometer
 	endOdometer := vehicle.FuelRecords[len(vehicle.FuelRecords)-1].Odometer
 
-	totalMiles := endOdometer - startOdometer
+	totalDistance := endOdometer.Sub(startOdometer)
+
+	// The average is left out when there are too few fill-ups, or when the
+	// fill-ups of a plug-in hybrid's tanks are interleaved.
+	economy, err := vehicle.AverageFuelEconomy()
+
+	switch {
+	case errors.Is(err, roadtrip.ErrNotEnoughFuelRecords), errors.Is(err, roadtrip.ErrInterleavedTanks):
+		fmt.Printf(" * Drove %.0f %s (%s)\n",
+			totalDistance.Value,
+			totalDistance.Unit,
+			err,
+		)
+	case err != nil:
+		return err
+	default:
+		fmt.Printf(" * Drove %.0f %s averaging %0.02f %s\n",
+			totalDistance.Value,
+			totalDistance.Unit,
+			economy.Value,
+			economy.Unit,
+		)
+	}
+
+	// Bi-fuel and plug-in hybrid vehicles have a summary for each tank, so
+	// gallons and kWh are never added together.
+	tanks, err := vehicle.TankSummaries()
+	if err != nil {
+		return err
+	}
 
-	// TODO: Support km, L, and km/L.
-	// numbers are correct, my labels are just hard-coded here
-	fmt.Printf(" * Drove %.0f miles averaging %0.02f mpg\n",
-		totalMiles,
-		(totalMiles / totalUnits),
-	)
+	for _, tank := range tanks {
+		if tank.FillUps == 0 {
+			continue
+		}
+
+		fmt.Printf(" * Spent %0.02f %s on %0.0f %s of fuel in %d fillups\n",
+			tank.Cost.Amount,
+			tank.Cost.Currency,
+			tank.Volume.Value,
+			tank.Volume.Unit,
+			tank.FillUps,
+		)
 
-	fmt.Printf(" * Spent $%0.02f on %0.0f gallons of fuel in %d fillups\n",
-		totalFuelCost,
-		totalUnits,
-		len(vehicle.FuelRecords),
-	)
+		if len(tanks) > 1 && tank.EconomyErr == nil {
+			fmt.Printf("   averaging %0.02f %s\n", tank.Economy.Value, tank.Economy.Unit)
+		}
+	}
 
 	fmt.Printf("\n")
 
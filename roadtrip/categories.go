@@ -0,0 +1,132 @@
+package roadtrip
+
+import (
+	"slices"
+	"strings"
+)
+
+// Categories is the list of categories assigned to a fuel, maintenance or
+// trip record. The app writes them in a single cell separated by commas.
+type Categories []string
+
+// HasCategory reports whether name is one of the categories, ignoring case.
+func (c Categories) HasCategory(name string) bool {
+	return slices.ContainsFunc(c, func(category string) bool {
+		return strings.EqualFold(category, name)
+	})
+}
+
+// String returns the categories as the app writes them.
+func (c Categories) String() string {
+	return strings.Join(c, ", ")
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Categories are separated
+// by commas or line breaks, and surrounding space and empty entries are
+// dropped.
+func (c *Categories) UnmarshalCSV(data []byte) error {
+	fields := strings.FieldsFunc(string(data), func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+
+	categories := make(Categories, 0, len(fields))
+
+	for _, field := range fields {
+		if category := strings.TrimSpace(field); category != "" {
+			categories = append(categories, category)
+		}
+	}
+
+	if len(categories) == 0 {
+		categories = nil
+	}
+
+	*c = categories
+
+	return nil
+}
+
+// CategoryUsage holds the indexes of the records in each section of a
+// [Vehicle] which are assigned a category.
+type CategoryUsage struct {
+	FuelRecords        []int
+	MaintenanceRecords []int
+	Trips              []int
+}
+
+// CategoryIndex returns every category used by the vehicle's fuel,
+// maintenance and trip records, mapped to the records which use it. Like
+// [Categories.HasCategory], categories are matched without regard to case,
+// so the index is keyed by the lowercase category name. Use
+// [Vehicle.Category] to look up a name as written, such as one returned by
+// [Vehicle.CategoryList].
+func (v Vehicle) CategoryIndex() map[string]CategoryUsage {
+	index := make(map[string]CategoryUsage)
+
+	add := func(categories Categories, record func(*CategoryUsage)) {
+		for _, category := range categories {
+			key := strings.ToLower(category)
+			usage := index[key]
+			record(&usage)
+			index[key] = usage
+		}
+	}
+
+	for i, fuel := range v.FuelRecords {
+		add(fuel.Categories, func(u *CategoryUsage) { u.FuelRecords = append(u.FuelRecords, i) })
+	}
+
+	for i, maintenance := range v.MaintenanceRecords {
+		add(maintenance.Categories, func(u *CategoryUsage) { u.MaintenanceRecords = append(u.MaintenanceRecords, i) })
+	}
+
+	for i, trip := range v.Trips {
+		add(trip.Categories, func(u *CategoryUsage) { u.Trips = append(u.Trips, i) })
+	}
+
+	return index
+}
+
+// Category returns the records which use the named category, ignoring case,
+// and whether any record uses it.
+func (v Vehicle) Category(name string) (CategoryUsage, bool) {
+	usage, ok := v.CategoryIndex()[strings.ToLower(name)]
+
+	return usage, ok
+}
+
+// CategoryList returns the sorted names of every category used by the
+// vehicle's records. Categories which differ only in case are listed once,
+// as first written in the file, so the names are not the keys of
+// [Vehicle.CategoryIndex]; look them up with [Vehicle.Category].
+func (v Vehicle) CategoryList() []string {
+	var (
+		categories []string
+		seen       = make(map[string]bool)
+	)
+
+	add := func(names Categories) {
+		for _, category := range names {
+			if key := strings.ToLower(category); !seen[key] {
+				seen[key] = true
+				categories = append(categories, category)
+			}
+		}
+	}
+
+	for _, fuel := range v.FuelRecords {
+		add(fuel.Categories)
+	}
+
+	for _, maintenance := range v.MaintenanceRecords {
+		add(maintenance.Categories)
+	}
+
+	for _, trip := range v.Trips {
+		add(trip.Categories)
+	}
+
+	slices.Sort(categories)
+
+	return categories
+}
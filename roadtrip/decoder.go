@@ -0,0 +1,36 @@
+package roadtrip
+
+import (
+	"io"
+)
+
+// A Decoder reads and parses a Road Trip vehicle data file from an input
+// stream, such as an HTTP request body or standard input.
+type Decoder struct {
+	r       io.Reader
+	options VehicleOptions
+}
+
+// NewDecoder returns a new [Decoder] that reads from r. The options are
+// applied to every [Vehicle] populated by the decoder.
+func NewDecoder(r io.Reader, options VehicleOptions) *Decoder {
+	return &Decoder{
+		r:       r,
+		options: options,
+	}
+}
+
+// Decode reads the complete Road Trip data file from its input and stores
+// the parsed contents in the [Vehicle] pointed to by v.
+func (d *Decoder) Decode(v *Vehicle) error {
+	var buf RawFileData
+
+	buf, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	v.setOptions(d.options)
+
+	return v.UnmarshalRoadtrip(buf)
+}
@@ -0,0 +1,141 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// A DistanceUnit identifies the unit in which a [Distance] is measured.
+type DistanceUnit string
+
+const (
+	// Miles is the unit written by Road Trip as "mi".
+	Miles DistanceUnit = "mi"
+
+	// Kilometers is the unit written by Road Trip as "km".
+	Kilometers DistanceUnit = "km"
+
+	// kilometersPerMile is the exact length of the international mile.
+	kilometersPerMile = 1.609344
+)
+
+// ErrUnknownDistanceUnit is returned when a distance unit is not recognized.
+var ErrUnknownDistanceUnit = errors.New("unknown distance unit")
+
+// ParseDistanceUnit returns the [DistanceUnit] for a unit as written in a
+// column header or the VEHICLE section, such as "mi", "mi." or "km".
+func ParseDistanceUnit(s string) (DistanceUnit, error) {
+	switch strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), ".")) {
+	case "mi":
+		return Miles, nil
+	case "km":
+		return Kilometers, nil
+	default:
+		return "", fmt.Errorf("%w '%s'", ErrUnknownDistanceUnit, s)
+	}
+}
+
+// A Distance is an odometer reading or travelled distance together with the
+// unit it was recorded in.
+//
+// The unit is taken from the column header when the header names one, such as
+// "Odometer (km)", and otherwise from the Odometer setting of the
+// [VehicleRecord]. A Distance with an empty Unit could not be attributed to
+// either and is never converted.
+type Distance struct {
+	Value float64
+	Unit  DistanceUnit
+}
+
+// In returns the distance converted to unit.
+func (d Distance) In(unit DistanceUnit) Distance {
+	switch {
+	case d.Unit == unit, d.Unit == "", unit == "":
+		return d
+	case unit == Kilometers:
+		return Distance{Value: d.Value * kilometersPerMile, Unit: unit}
+	default:
+		return Distance{Value: d.Value / kilometersPerMile, Unit: unit}
+	}
+}
+
+// Miles returns the distance in miles.
+func (d Distance) Miles() float64 {
+	return d.In(Miles).Value
+}
+
+// Kilometers returns the distance in kilometers.
+func (d Distance) Kilometers() float64 {
+	return d.In(Kilometers).Value
+}
+
+// Add returns the sum of two distances in the unit of d.
+func (d Distance) Add(o Distance) Distance {
+	return Distance{Value: d.Value + o.In(d.Unit).Value, Unit: d.Unit}
+}
+
+// Sub returns the difference between two distances in the unit of d.
+func (d Distance) Sub(o Distance) Distance {
+	return Distance{Value: d.Value - o.In(d.Unit).Value, Unit: d.Unit}
+}
+
+// String returns the distance and its unit, such as "621 mi".
+func (d Distance) String() string {
+	return formatQuantity(d.Value, string(d.Unit))
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Only the value is read
+// from the cell, the unit is assigned from the column header or vehicle.
+func (d *Distance) UnmarshalCSV(data []byte) error {
+	value, err := parseCSVFloat(data)
+	if err != nil {
+		return err
+	}
+
+	d.Value = value
+
+	return nil
+}
+
+// distanceHeader matches column headers which name a distance unit, such as
+// "Odometer (mi)" or "Start Odometer (km.)".
+var distanceHeader = regexp.MustCompile(`^(.*)\((mi|km)(\.?)\)$`)
+
+// normalizeDistanceHeader rewrites a column header which names a distance
+// unit into the canonical miles form used in the struct tags, and returns the
+// unit it named.
+func normalizeDistanceHeader(column string) (string, DistanceUnit) {
+	match := distanceHeader.FindStringSubmatch(column)
+	if match == nil {
+		return column, ""
+	}
+
+	unit, _ := ParseDistanceUnit(match[2])
+
+	return match[1] + "(" + string(Miles) + match[3] + ")", unit
+}
+
+// setDistanceUnits assigns unit to every [Distance] field of the record
+// struct that target points to which does not have a unit yet.
+func setDistanceUnits(target any, unit DistanceUnit) {
+	if unit == "" {
+		return
+	}
+
+	record := reflect.ValueOf(target).Elem()
+	distanceType := reflect.TypeFor[Distance]()
+
+	for i := range record.NumField() {
+		field := record.Field(i)
+		if field.Type() != distanceType || !field.CanSet() {
+			continue
+		}
+
+		if d, ok := field.Addr().Interface().(*Distance); ok && d.Unit == "" {
+			d.Unit = unit
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// An EconomyUnit identifies the unit in which a [FuelEconomy] is measured.
+type EconomyUnit string
+
+const (
+	// MilesPerGallon is miles per US gallon, written by Road Trip as "MPG".
+	MilesPerGallon EconomyUnit = "MPG"
+
+	// MilesPerImperialGallon is miles per imperial gallon.
+	MilesPerImperialGallon EconomyUnit = "MPG (Imp)"
+
+	// KilometersPerLiter is kilometers per litre.
+	KilometersPerLiter EconomyUnit = "km/L"
+
+	// LitersPer100Kilometers is litres consumed per 100 kilometers, where
+	// lower values are more economical.
+	LitersPer100Kilometers EconomyUnit = "L/100km"
+
+	// MilesPerKilowattHour is miles per kilowatt-hour for electric vehicles.
+	MilesPerKilowattHour EconomyUnit = "mi/kWh"
+
+	// KilometersPerKilowattHour is kilometers per kilowatt-hour for electric
+	// vehicles.
+	KilometersPerKilowattHour EconomyUnit = "km/kWh"
+
+	// KilowattHoursPer100Kilometers is kilowatt-hours consumed per 100
+	// kilometers, where lower values are more efficient.
+	KilowattHoursPer100Kilometers EconomyUnit = "kWh/100km"
+
+	// per100 is the distance scale of the consumption units.
+	per100 = 100
+)
+
+var (
+	// ErrUnknownEconomyUnit is returned when a fuel economy unit is not
+	// recognized.
+	ErrUnknownEconomyUnit = errors.New("unknown fuel economy unit")
+
+	// ErrNotEnoughFuelRecords is returned when a fuel economy cannot be
+	// calculated because there are fewer than two fill-ups.
+	ErrNotEnoughFuelRecords = errors.New("not enough fuel records")
+)
+
+// economyUnits describes each [EconomyUnit] as a distance and volume unit
+// pair. Consumption units measure volume per distance and are inverted.
+var economyUnits = map[EconomyUnit]struct {
+	distance DistanceUnit
+	volume   VolumeUnit
+	inverse  bool
+}{
+	MilesPerGallon:                {distance: Miles, volume: USGallons},
+	MilesPerImperialGallon:        {distance: Miles, volume: ImperialGallons},
+	KilometersPerLiter:            {distance: Kilometers, volume: Liters},
+	LitersPer100Kilometers:        {distance: Kilometers, volume: Liters, inverse: true},
+	MilesPerKilowattHour:          {distance: Miles, volume: KilowattHours},
+	KilometersPerKilowattHour:     {distance: Kilometers, volume: KilowattHours},
+	KilowattHoursPer100Kilometers: {distance: Kilometers, volume: KilowattHours, inverse: true},
+}
+
+// ParseEconomyUnit returns the [EconomyUnit] for a unit as written in the
+// VEHICLE section, such as "MPG" or "L/100km".
+func ParseEconomyUnit(s string) (EconomyUnit, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(s), ""))
+
+	switch normalized {
+	case "mpg", "mpg(us)":
+		return MilesPerGallon, nil
+	case "mpg(imp)", "mpg(uk)":
+		return MilesPerImperialGallon, nil
+	case "km/l":
+		return KilometersPerLiter, nil
+	case "l/100km":
+		return LitersPer100Kilometers, nil
+	case "mi/kwh":
+		return MilesPerKilowattHour, nil
+	case "km/kwh":
+		return KilometersPerKilowattHour, nil
+	case "kwh/100km":
+		return KilowattHoursPer100Kilometers, nil
+	default:
+		return "", fmt.Errorf("%w '%s'", ErrUnknownEconomyUnit, s)
+	}
+}
+
+// IsEnergy reports whether the unit measures the efficiency of an electric
+// vehicle.
+func (u EconomyUnit) IsEnergy() bool {
+	return economyUnits[u].volume.IsEnergy()
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Recognized units are
+// normalized to the constants of this package, and any other value is kept
+// as written.
+func (u *EconomyUnit) UnmarshalCSV(data []byte) error {
+	unit, err := ParseEconomyUnit(string(data))
+	if err != nil {
+		unit = EconomyUnit(data)
+	}
+
+	*u = unit
+
+	return nil
+}
+
+// A FuelEconomy is a fuel economy or energy efficiency figure together with
+// the unit it is measured in.
+type FuelEconomy struct {
+	Value float64
+	Unit  EconomyUnit
+}
+
+// NewFuelEconomy returns the fuel economy of travelling distance on volume,
+// expressed in unit.
+func NewFuelEconomy(distance Distance, volume Volume, unit EconomyUnit) (FuelEconomy, error) {
+	spec, ok := economyUnits[unit]
+	if !ok {
+		return FuelEconomy{}, fmt.Errorf("%w '%s'", ErrUnknownEconomyUnit, unit)
+	}
+
+	volume, err := volume.In(spec.volume)
+	if err != nil {
+		return FuelEconomy{}, err
+	}
+
+	d := distance.In(spec.distance).Value
+
+	if spec.inverse {
+		if d == 0 {
+			return FuelEconomy{Unit: unit}, nil
+		}
+
+		return FuelEconomy{Value: volume.Value / d * per100, Unit: unit}, nil
+	}
+
+	if volume.Value == 0 {
+		return FuelEconomy{Unit: unit}, nil
+	}
+
+	return FuelEconomy{Value: d / volume.Value, Unit: unit}, nil
+}
+
+// In returns the fuel economy converted to unit. Liquid fuel units convert
+// between each other, but never to or from electric efficiency units.
+func (e FuelEconomy) In(unit EconomyUnit) (FuelEconomy, error) {
+	if e.Unit == unit {
+		return e, nil
+	}
+
+	from, ok := economyUnits[e.Unit]
+	if !ok {
+		return FuelEconomy{}, fmt.Errorf("%w '%s'", ErrUnknownEconomyUnit, e.Unit)
+	}
+
+	// Express the figure as a distance travelled on a volume and convert
+	// that pair into the new unit.
+	distance := Distance{Value: e.Value, Unit: from.distance}
+	volume := Volume{Value: 1, Unit: from.volume}
+
+	if from.inverse {
+		distance = Distance{Value: per100, Unit: from.distance}
+		volume = Volume{Value: e.Value, Unit: from.volume}
+	}
+
+	return NewFuelEconomy(distance, volume, unit)
+}
+
+// String returns the fuel economy and its unit, such as "18.94 MPG".
+func (e FuelEconomy) String() string {
+	return formatQuantity(e.Value, string(e.Unit))
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Only the value is read
+// from the cell, the unit is assigned from the vehicle's Units setting.
+func (e *FuelEconomy) UnmarshalCSV(data []byte) error {
+	value, err := parseCSVFloat(data)
+	if err != nil {
+		return err
+	}
+
+	e.Value = value
+
+	return nil
+}
+
+// EconomyUnit returns the fuel economy unit the vehicle is configured to
+// display, falling back to [MilesPerGallon] when it is not known.
+func (v Vehicle) EconomyUnit() EconomyUnit {
+	if info, err := v.Info(); err == nil {
+		if _, ok := economyUnits[info.Units]; ok {
+			return info.Units
+		}
+	}
+
+	return MilesPerGallon
+}
+
+// AverageFuelEconomy returns the fuel economy over every fill-up of the
+// [PrimaryTank], in the vehicle's [Vehicle.EconomyUnit]. The distance is
+// measured between the first and last full fill-up, restarting at each reset
+// fill-up, and the fuel of the first full fill-up is excluded because it was
+// burned before the log began. See [Vehicle.TankFuelEconomy] for the other
+// tank of a bi-fuel vehicle, and for the [ErrInterleavedTanks] returned when
+// both tanks were filled in turn.
+func (v Vehicle) AverageFuelEconomy() (FuelEconomy, error) {
+	return v.tankFuelEconomy(PrimaryTank, v.EconomyUnit())
+}
+
+// averageFuelEconomy implements [Vehicle.AverageFuelEconomy] for a list of
+// fill-ups in odometer order.
+func averageFuelEconomy(records []FuelRecord, unit EconomyUnit) (FuelEconomy, error) {
+	distance, consumed, err := fuelSpan(records)
+	if err != nil {
+		return FuelEconomy{}, err
+	}
+
+	volume := Volume{Unit: consumed[0].FillAmount.Unit}
+
+	for _, fuel := range consumed {
+		volume, err = volume.Add(fuel.FillAmount)
+		if err != nil {
+			return FuelEconomy{}, err
+		}
+	}
+
+	return NewFuelEconomy(distance, volume, unit)
+}
+
+// fuelSpan returns the distance driven between full fill-ups of records,
+// which are in odometer order, and the fill-ups whose fuel was used to drive
+// it. Only a full fill-up shows how much fuel has been used since the last
+// one, so partial fill-ups before the first or after the last full fill-up
+// are left out. A reset fill-up, which follows fill-ups missing from the
+// log, starts a new span, and the distance since the previous fill-up is
+// left out with it.
+func fuelSpan(records []FuelRecord) (Distance, []FuelRecord, error) {
+	var (
+		distance Distance
+		consumed []FuelRecord
+		start    int
+	)
+
+	for i := 1; i <= len(records); i++ {
+		if i < len(records) && !records[i].IsReset() {
+			continue
+		}
+
+		if d, c, ok := fullFillSpan(records[start:i]); ok {
+			if consumed == nil {
+				distance = d
+			} else {
+				distance = distance.Add(d)
+			}
+
+			consumed = append(consumed, c...)
+		}
+
+		start = i
+	}
+
+	if consumed == nil {
+		return Distance{}, nil, ErrNotEnoughFuelRecords
+	}
+
+	return distance, consumed, nil
+}
+
+// fullFillSpan returns the distance driven between the first and last full
+// fill-up of records and the fill-ups after the first, or false if records
+// hold fewer than two full fill-ups.
+func fullFillSpan(records []FuelRecord) (Distance, []FuelRecord, bool) {
+	first := slices.IndexFunc(records, isFullFill)
+
+	last := len(records) - 1
+	for last > first && !isFullFill(records[last]) {
+		last--
+	}
+
+	if first < 0 || last <= first {
+		return Distance{}, nil, false
+	}
+
+	return records[last].Odometer.Sub(records[first].Odometer), records[first+1 : last+1], true
+}
+
+// isFullFill reports whether the fill-up filled the tank.
+func isFullFill(fuel FuelRecord) bool {
+	return !bool(fuel.PartialFill)
+}
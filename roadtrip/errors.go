@@ -0,0 +1,83 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A ParseError describes a problem decoding a single row or cell of a Road
+// Trip data file. Use [errors.As] to retrieve it from an error returned by
+// this package.
+type ParseError struct {
+	// Section is the canonical section header of the section being parsed,
+	// or the header as written for a [RawSection].
+	Section string
+
+	// Line is the 1-based line number within the data file. For a cell
+	// error it is the line on which the cell begins.
+	Line int
+
+	// Column is the column name, as written in the file, of the cell which
+	// failed to parse. It is empty for errors which affect the whole row.
+	Column string
+
+	// Value is the raw text of the cell which failed to parse.
+	Value string
+
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Section)
+
+	if e.Line > 0 {
+		fmt.Fprintf(&b, " line %d", e.Line)
+	}
+
+	if e.Column != "" {
+		fmt.Fprintf(&b, " column '%s'", e.Column)
+	}
+
+	if e.Value != "" {
+		fmt.Fprintf(&b, " value '%s'", e.Value)
+	}
+
+	fmt.Fprintf(&b, ": %v", e.Err)
+
+	return b.String()
+}
+
+// Unwrap returns the underlying cause of the [ParseError].
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseErrorList flattens err, which may join several errors together, into
+// the list of [ParseError] values it holds. Errors of any other type are
+// wrapped in a [ParseError] without position.
+func parseErrorList(err error) []*ParseError {
+	if err == nil {
+		return nil
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var list []*ParseError
+		for _, e := range joined.Unwrap() {
+			list = append(list, parseErrorList(e)...)
+		}
+
+		return list
+	}
+
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return []*ParseError{parseErr}
+	}
+
+	return []*ParseError{{Err: err}}
+}
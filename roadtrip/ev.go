@@ -0,0 +1,111 @@
+package roadtrip
+
+import "fmt"
+
+// IsCharge reports whether the fill-up is an electric vehicle charging
+// session, recorded in [KilowattHours].
+func (v FuelRecord) IsCharge() bool {
+	return v.FillAmount.Unit.IsEnergy()
+}
+
+// IsPartialCharge reports whether the fill-up is a charging session which
+// did not charge the battery to the level the vehicle is normally charged
+// to. Like a partial fill-up, it does not show how much energy has been used
+// since the previous charge, so it is carried into the next full charge when
+// calculating efficiency.
+func (v FuelRecord) IsPartialCharge() bool {
+	return v.IsCharge() && bool(v.PartialFill)
+}
+
+// ChargingSessions returns the fill-ups which are electric vehicle charging
+// sessions, in odometer order.
+func (v Vehicle) ChargingSessions() []FuelRecord {
+	return v.fuelRecordsFunc(FuelRecord.IsCharge)
+}
+
+// chargingSpan returns the charging sessions, or [ErrInterleavedTanks] if a
+// plug-in hybrid took on fuel between them, in which case the distance
+// between charges was partly driven on fuel.
+func (v Vehicle) chargingSpan() ([]FuelRecord, error) {
+	sessions := v.ChargingSessions()
+
+	if err := v.checkInterleaved(sessions, FuelRecord.IsCharge); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// ChargingEfficiency returns the energy efficiency of the vehicle over its
+// charging sessions, in unit, such as [MilesPerKilowattHour] or
+// [KilowattHoursPer100Kilometers]. The distance is measured between the
+// first and last full charge, and [ErrInterleavedTanks] is returned for a
+// plug-in hybrid which took on fuel in between.
+func (v Vehicle) ChargingEfficiency(unit EconomyUnit) (FuelEconomy, error) {
+	if !unit.IsEnergy() {
+		return FuelEconomy{}, fmt.Errorf("%w: %s is not an energy efficiency unit", ErrIncompatibleUnits, unit)
+	}
+
+	sessions, err := v.chargingSpan()
+	if err != nil {
+		return FuelEconomy{}, err
+	}
+
+	return averageFuelEconomy(sessions, unit)
+}
+
+// ChargingEnergy returns the total energy of every charging session.
+func (v Vehicle) ChargingEnergy() Volume {
+	total := Volume{Unit: KilowattHours}
+
+	for _, session := range v.ChargingSessions() {
+		total.Value += session.FillAmount.Value
+	}
+
+	return total
+}
+
+// ChargingCostPerKilowattHour returns the average price paid per
+// kilowatt-hour over every charging session, in the vehicle's
+// [Vehicle.HomeCurrency]. Free charging sessions lower the average.
+func (v Vehicle) ChargingCostPerKilowattHour() (Money, error) {
+	energy := v.ChargingEnergy()
+	if energy.Value == 0 {
+		return Money{}, ErrNotEnoughFuelRecords
+	}
+
+	cost, err := v.fuelCost(v.ChargingSessions())
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: cost.Amount / energy.Value, Currency: cost.Currency}, nil
+}
+
+// ChargingCostPerDistance returns the cost of the energy used to drive one
+// mile or kilometer, as given by unit, in the vehicle's
+// [Vehicle.HomeCurrency]. Like [Vehicle.ChargingEfficiency], it is measured
+// between the first and last full charge.
+func (v Vehicle) ChargingCostPerDistance(unit DistanceUnit) (Money, error) {
+	sessions, err := v.chargingSpan()
+	if err != nil {
+		return Money{}, err
+	}
+
+	distance, consumed, err := fuelSpan(sessions)
+	if err != nil {
+		return Money{}, err
+	}
+
+	d := distance.In(unit).Value
+	if d == 0 {
+		return Money{}, ErrNotEnoughFuelRecords
+	}
+
+	cost, err := v.fuelCost(consumed)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: cost.Amount / d, Currency: cost.Currency}, nil
+}
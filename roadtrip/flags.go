@@ -0,0 +1,64 @@
+package roadtrip
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Flags is the bitfield written in the Flags column of every section. The
+// full value is kept so that bits whose meaning is not yet known are never
+// lost.
+//
+// The meaning of each bit is not documented by the app, so no bit is decoded
+// into a typed accessor until its meaning has been confirmed. The flags of
+// every section can be inspected with [Flags.Has] and [Flags.Bits].
+type Flags uint64
+
+// Has reports whether every bit of mask is set.
+func (f Flags) Has(mask Flags) bool {
+	return f&mask == mask
+}
+
+// Bits returns the positions of the bits which are set, lowest first.
+func (f Flags) Bits() []int {
+	var positions []int
+
+	for f != 0 {
+		position := bits.TrailingZeros64(uint64(f))
+		positions = append(positions, position)
+		f &^= 1 << position
+	}
+
+	return positions
+}
+
+// String returns the raw value as written in the data file.
+func (f Flags) String() string {
+	return strconv.FormatUint(uint64(f), 10)
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. An empty cell has no
+// flags set.
+func (f *Flags) UnmarshalCSV(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		*f = 0
+		return nil
+	}
+
+	value, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	*f = Flags(value)
+
+	return nil
+}
+
+// IsReset reports whether the fill-up restarts the fuel economy calculation,
+// as marked in its Reset column. Its Flags are not consulted.
+func (v FuelRecord) IsReset() bool {
+	return bool(v.Reset)
+}
@@ -0,0 +1,112 @@
+package roadtrip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// FileInfoPrefix is the literal text at the start of the first line of
+	// every Road Trip vehicle data file.
+	FileInfoPrefix = "ROAD TRIP CSV"
+
+	// DefaultDelimiters is the field and decimal separator pair used by
+	// Road Trip when running in an English locale.
+	DefaultDelimiters Delimiters = ",."
+)
+
+// ErrMissingFileInfo is returned when the data does not begin with the
+// Road Trip file info line.
+var ErrMissingFileInfo = errors.New("missing Road Trip file info line")
+
+// Delimiters holds the two characters declared in the file info line at the
+// top of each Road Trip data file. The first character is the CSV field
+// separator and the second is the decimal separator used in numeric values.
+//
+// An English export declares `",."` while many European locales declare
+// `";,"`.
+type Delimiters string
+
+// Field returns the CSV field separator.
+func (d Delimiters) Field() rune {
+	r, _ := utf8.DecodeRuneInString(d.orDefault())
+	return r
+}
+
+// Decimal returns the decimal separator used for numeric values.
+func (d Delimiters) Decimal() rune {
+	s := d.orDefault()
+	_, size := utf8.DecodeRuneInString(s)
+	r, _ := utf8.DecodeRuneInString(s[size:])
+
+	return r
+}
+
+// NormalizeDecimal rewrites a numeric value using the file's decimal
+// separator into the dot-decimal form understood by [strconv.ParseFloat].
+func (d Delimiters) NormalizeDecimal(s string) string {
+	decimal := d.Decimal()
+	if decimal == '.' {
+		return s
+	}
+
+	return strings.Replace(s, string(decimal), ".", 1)
+}
+
+// orDefault returns the delimiter pair, falling back to [DefaultDelimiters]
+// when the value is malformed.
+func (d Delimiters) orDefault() string {
+	if utf8.RuneCountInString(string(d)) != 2 {
+		return string(DefaultDelimiters)
+	}
+
+	return string(d)
+}
+
+// ParseDelimiters extracts the field and decimal separators from the file
+// info line, for example `ROAD TRIP CSV ",."`.
+func ParseDelimiters(line string) (Delimiters, error) {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "\ufeff")
+
+	rest, ok := strings.CutPrefix(line, FileInfoPrefix)
+	if !ok {
+		return "", ErrMissingFileInfo
+	}
+
+	rest = strings.TrimSpace(rest)
+	if len(rest) < 2 || rest[0] != '"' || rest[len(rest)-1] != '"' {
+		return "", fmt.Errorf("malformed file info line '%s'", line)
+	}
+
+	delimiters := Delimiters(rest[1 : len(rest)-1])
+	if utf8.RuneCountInString(string(delimiters)) != 2 {
+		return "", fmt.Errorf("malformed delimiters in file info line '%s'", line)
+	}
+
+	return delimiters, nil
+}
+
+// FileInfoLine returns the first line of the raw file data, which is
+// expected to be the Road Trip file info line.
+func (fileData *RawFileData) FileInfoLine() string {
+	line, _, _ := bytes.Cut(*fileData, []byte("\n"))
+
+	return string(bytes.TrimRight(line, "\r"))
+}
+
+// Delimiters parses the file info line of the raw file data and returns the
+// declared field and decimal separators.
+func (fileData *RawFileData) Delimiters() (Delimiters, error) {
+	return ParseDelimiters(fileData.FileInfoLine())
+}
+
+// fieldDelimiter returns the declared field separator of the raw file data,
+// or the default separator when the file info line is malformed.
+func (fileData *RawFileData) fieldDelimiter() rune {
+	delimiters, _ := fileData.Delimiters()
+
+	return delimiters.Field()
+}
@@ -0,0 +1,224 @@
+package roadtrip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language of the canonical section headers and
+// column names used in the struct tags throughout this package.
+const DefaultLanguage = "en"
+
+// A LanguagePack maps the localized section headers and column names written
+// by Road Trip in a single language to the canonical English names used in
+// the `roadtrip` and `csv` struct tags of this package.
+//
+// Headers and columns which are missing from the maps are assumed to be
+// written in English.
+type LanguagePack struct {
+	// Language is the value the app writes in the Language column of the
+	// data file, such as "fr".
+	Language string
+
+	// Sections maps each localized section header to its canonical header,
+	// for example "PLEINS" to "FUEL RECORDS".
+	Sections map[string]string
+
+	// Columns maps each localized column name to its canonical column name,
+	// for example "Odomètre (mi)" to "Odometer (mi)".
+	Columns map[string]string
+}
+
+// SectionHeader returns the localized section header for a canonical header.
+// When the pack has several aliases for the header, the first in sorted order
+// is returned.
+func (p LanguagePack) SectionHeader(canonical string) string {
+	return localizedName(p.Sections, canonical)
+}
+
+// ColumnName returns the localized column name for a canonical column name.
+// When the pack has several aliases for the column, the first in sorted
+// order is returned.
+func (p LanguagePack) ColumnName(canonical string) string {
+	return localizedName(p.Columns, canonical)
+}
+
+// localizedName returns the first localized name, in sorted order, which
+// names maps to canonical, or canonical itself if there is none.
+func localizedName(names map[string]string, canonical string) string {
+	var aliases []string
+
+	for localized, c := range names {
+		if c == canonical {
+			aliases = append(aliases, localized)
+		}
+	}
+
+	if len(aliases) == 0 {
+		return canonical
+	}
+
+	return slices.Min(aliases)
+}
+
+// sectionHeaders returns every section header the pack recognizes, mapped to
+// its canonical header. This includes each alias in Sections and the
+// canonical headers which the pack does not translate.
+func (p LanguagePack) sectionHeaders() map[string]string {
+	headers := make(map[string]string, len(p.Sections)+len(SectionHeaderList()))
+
+	canonical := SectionHeaderList()
+	translated := slices.Collect(maps.Values(p.Sections))
+
+	for _, header := range canonical {
+		if !slices.Contains(translated, header) {
+			headers[header] = header
+		}
+	}
+
+	for localized, header := range p.Sections {
+		if slices.Contains(canonical, header) {
+			headers[localized] = header
+		}
+	}
+
+	return headers
+}
+
+// CanonicalSectionHeader returns the canonical section header for a
+// localized header.
+func (p LanguagePack) CanonicalSectionHeader(localized string) string {
+	if canonical, ok := p.Sections[localized]; ok {
+		return canonical
+	}
+
+	return localized
+}
+
+// CanonicalColumnName returns the canonical column name for a localized
+// column name.
+func (p LanguagePack) CanonicalColumnName(localized string) string {
+	if canonical, ok := p.Columns[localized]; ok {
+		return canonical
+	}
+
+	return localized
+}
+
+var (
+	languagePacksMu sync.RWMutex
+	languagePacks   = map[string]LanguagePack{
+		DefaultLanguage: {Language: DefaultLanguage},
+	}
+)
+
+// RegisterLanguagePack adds a [LanguagePack] to the set used when parsing
+// data files, replacing any existing pack for the same Language.
+func RegisterLanguagePack(pack LanguagePack) error {
+	if pack.Language == "" {
+		return errors.New("cannot register language pack without a Language")
+	}
+
+	languagePacksMu.Lock()
+	defer languagePacksMu.Unlock()
+
+	languagePacks[pack.Language] = pack
+
+	return nil
+}
+
+// LookupLanguagePack returns the registered [LanguagePack] for a language.
+func LookupLanguagePack(language string) (LanguagePack, bool) {
+	languagePacksMu.RLock()
+	defer languagePacksMu.RUnlock()
+
+	pack, ok := languagePacks[language]
+
+	return pack, ok
+}
+
+// LanguagePackList returns the languages of every registered
+// [LanguagePack] in sorted order.
+func LanguagePackList() []string {
+	languagePacksMu.RLock()
+	defer languagePacksMu.RUnlock()
+
+	languages := make([]string, 0, len(languagePacks))
+	for language := range languagePacks {
+		languages = append(languages, language)
+	}
+
+	slices.Sort(languages)
+
+	return languages
+}
+
+// DetectLanguagePack chooses the registered [LanguagePack] whose section
+// headers best match the lines of the raw file data. It returns false if no
+// pack recognizes any section header.
+func (fileData *RawFileData) DetectLanguagePack() (LanguagePack, bool) {
+	lines := make(map[string]bool)
+	for _, line := range bytes.Split(*fileData, []byte("\n")) {
+		lines[strings.TrimSpace(string(line))] = true
+	}
+
+	var (
+		best      LanguagePack
+		bestScore int
+	)
+
+	for _, language := range LanguagePackList() {
+		pack, _ := LookupLanguagePack(language)
+
+		score := 0
+
+		for header := range pack.sectionHeaders() {
+			if lines[header] {
+				score++
+			}
+		}
+
+		if score > bestScore {
+			best, bestScore = pack, score
+		}
+	}
+
+	return best, bestScore > 0
+}
+
+// LanguagePack returns the [LanguagePack] for the raw file data. The pack is
+// chosen by the Language declared in the file, falling back to detection by
+// section header when that language has no registered pack.
+func (fileData *RawFileData) LanguagePack() (LanguagePack, error) {
+	_, language, err := fileData.VersionInfo()
+	if err != nil {
+		return LanguagePack{}, err
+	}
+
+	if pack, ok := LookupLanguagePack(language); ok {
+		return pack, nil
+	}
+
+	if pack, ok := fileData.DetectLanguagePack(); ok {
+		return pack, nil
+	}
+
+	return LanguagePack{}, fmt.Errorf("%w '%s' (registered: %v)",
+		ErrUnsupportedLanguage, language, LanguagePackList())
+}
+
+// languagePackOrDefault returns the [LanguagePack] for the raw file data or
+// the English pack when none can be found.
+func (fileData *RawFileData) languagePackOrDefault() LanguagePack {
+	pack, err := fileData.LanguagePack()
+	if err != nil {
+		pack, _ = LookupLanguagePack(DefaultLanguage)
+	}
+
+	return pack
+}
@@ -0,0 +1,217 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A Currency is an ISO 4217 alphabetic currency code such as "USD".
+type Currency string
+
+var (
+	// ErrUnknownCurrency is returned when a currency code is not a valid
+	// ISO 4217 code.
+	ErrUnknownCurrency = errors.New("unknown currency")
+
+	// ErrCurrencyMismatch is returned when adding amounts of money in
+	// different currencies.
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+
+	// ErrMissingCurrencyRate is returned when an amount in a foreign
+	// currency has no exchange rate to the home currency.
+	ErrMissingCurrencyRate = errors.New("missing currency rate")
+)
+
+// numericCurrencies maps the ISO 4217 numeric codes of common currencies to
+// their alphabetic codes.
+var numericCurrencies = map[string]Currency{
+	"036": "AUD",
+	"124": "CAD",
+	"156": "CNY",
+	"203": "CZK",
+	"208": "DKK",
+	"344": "HKD",
+	"356": "INR",
+	"392": "JPY",
+	"410": "KRW",
+	"484": "MXN",
+	"554": "NZD",
+	"578": "NOK",
+	"702": "SGD",
+	"710": "ZAR",
+	"752": "SEK",
+	"756": "CHF",
+	"826": "GBP",
+	"840": "USD",
+	"978": "EUR",
+	"985": "PLN",
+	"986": "BRL",
+}
+
+// ParseCurrency returns the [Currency] for an ISO 4217 alphabetic code, or for
+// the numeric code of a common currency. An empty string is returned as an
+// empty Currency.
+func ParseCurrency(s string) (Currency, error) {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case s == "":
+		return "", nil
+	case isAlphaCode(s):
+		return Currency(strings.ToUpper(s)), nil
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		if currency, ok := numericCurrencies[fmt.Sprintf("%03d", n)]; ok {
+			return currency, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w '%s'", ErrUnknownCurrency, s)
+}
+
+// isAlphaCode reports whether s has the form of an alphabetic currency code.
+func isAlphaCode(s string) bool {
+	if len(s) != 3 { //nolint:mnd // ISO 4217 codes are three letters
+		return false
+	}
+
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Numeric codes are
+// translated to alphabetic codes where known, and any other value is kept as
+// written.
+func (c *Currency) UnmarshalCSV(data []byte) error {
+	currency, err := ParseCurrency(string(data))
+	if err != nil {
+		currency = Currency(data)
+	}
+
+	*c = currency
+
+	return nil
+}
+
+// Money is an amount together with its currency. The currency of an amount
+// is taken from the record's Currency Code, or from the vehicle's Home
+// Currency when the record does not name one.
+type Money struct {
+	Amount   float64
+	Currency Currency
+}
+
+// Exchange returns the amount converted into the currency to using rate, the
+// number of units of to per unit of the amount's currency. Amounts already in
+// to, or without a currency, are returned in to unchanged.
+func (m Money) Exchange(rate float64, to Currency) (Money, error) {
+	if m.Currency == to || m.Currency == "" {
+		return Money{Amount: m.Amount, Currency: to}, nil
+	}
+
+	if rate <= 0 {
+		return Money{}, fmt.Errorf("%w from %s to %s", ErrMissingCurrencyRate, m.Currency, to)
+	}
+
+	return Money{Amount: m.Amount * rate, Currency: to}, nil
+}
+
+// Add returns the sum of two amounts, which must be in the same currency.
+// An amount without a currency takes the currency of the other.
+func (m Money) Add(o Money) (Money, error) {
+	switch {
+	case m.Currency == "":
+		m.Currency = o.Currency
+	case o.Currency != "" && o.Currency != m.Currency:
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// String returns the amount and its currency, such as "71.3 USD".
+func (m Money) String() string {
+	return formatQuantity(m.Amount, string(m.Currency))
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Only the amount is read
+// from the cell, the currency is assigned from the record or vehicle.
+func (m *Money) UnmarshalCSV(data []byte) error {
+	amount, err := parseCSVFloat(data)
+	if err != nil {
+		return err
+	}
+
+	m.Amount = amount
+
+	return nil
+}
+
+// setDefaultCurrency assigns currency to an amount which does not have one.
+func setDefaultCurrency(m *Money, currency Currency) {
+	if m.Currency == "" {
+		m.Currency = currency
+	}
+}
+
+// HomeCurrency returns the vehicle's Home Currency, or an empty Currency if
+// the VEHICLE section does not hold exactly one row.
+func (v Vehicle) HomeCurrency() Currency {
+	info, _ := v.Info()
+
+	return info.HomeCurrency
+}
+
+// TotalFuelCost returns the total price of every fill-up converted to the
+// vehicle's [Vehicle.HomeCurrency].
+func (v Vehicle) TotalFuelCost() (Money, error) {
+	return v.fuelCost(v.FuelRecords)
+}
+
+// fuelCost returns the total price of the fill-ups in records converted to
+// the vehicle's [Vehicle.HomeCurrency].
+func (v Vehicle) fuelCost(records []FuelRecord) (Money, error) {
+	total := Money{Currency: v.HomeCurrency()}
+
+	for _, fuel := range records {
+		price, err := fuel.HomeTotalPrice(v.HomeCurrency())
+		if err != nil {
+			return Money{}, err
+		}
+
+		total, err = total.Add(price)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+
+	return total, nil
+}
+
+// TotalMaintenanceCost returns the total cost of every maintenance record
+// converted to the vehicle's [Vehicle.HomeCurrency].
+func (v Vehicle) TotalMaintenanceCost() (Money, error) {
+	total := Money{Currency: v.HomeCurrency()}
+
+	for _, maintenance := range v.MaintenanceRecords {
+		cost, err := maintenance.HomeCost(v.HomeCurrency())
+		if err != nil {
+			return Money{}, err
+		}
+
+		total, err = total.Add(cost)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+
+	return total, nil
+}
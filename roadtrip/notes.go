@@ -0,0 +1,102 @@
+package roadtrip
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxAttributeKeyLength is the longest line prefix which is treated as the
+// key of a "Key: value" line in the vehicle's Notes.
+const maxAttributeKeyLength = 32
+
+// parseNoteAttributes extracts the "Key: value" lines of a Notes block, such
+// as the "License Plate:" and "VIN:" lines of the app's template, into a map.
+// Lines which are not key lines are appended to the value of the preceding
+// key, so free text below "Notes:" is kept. Keys without a value are
+// omitted.
+func parseNoteAttributes(notes string) map[string]string {
+	attributes := make(map[string]string)
+
+	var key string
+
+	for _, line := range strings.Split(strings.ReplaceAll(notes, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if k, value, ok := noteAttribute(line); ok {
+			key = k
+
+			if value != "" {
+				attributes[key] = value
+			}
+
+			continue
+		}
+
+		if key == "" {
+			continue
+		}
+
+		if attributes[key] == "" {
+			attributes[key] = line
+		} else {
+			attributes[key] += "\n" + line
+		}
+	}
+
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	return attributes
+}
+
+// noteAttribute splits a "Key: value" line. The key must be short and made
+// of words, and be followed by the end of the line or a space, so that free
+// text which happens to contain a colon, such as a time of day, is not
+// mistaken for a key.
+func noteAttribute(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, ":")
+	if !ok || (value != "" && !unicode.IsSpace([]rune(value)[0])) {
+		return "", "", false
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > maxAttributeKeyLength || !unicode.IsLetter([]rune(key)[0]) {
+		return "", "", false
+	}
+
+	for _, r := range key {
+		if unicode.IsDigit(r) {
+			return "", "", false
+		}
+	}
+
+	return key, strings.TrimSpace(value), true
+}
+
+// Attribute returns the value of the "Key: value" line of the vehicle's
+// Notes named by key, which is matched without regard to case.
+func (v VehicleRecord) Attribute(key string) (string, bool) {
+	if value, ok := v.Attributes[key]; ok {
+		return value, true
+	}
+
+	for k, value := range v.Attributes {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// VIN returns the Vehicle Identification Number recorded on the "VIN:" line
+// of the vehicle's Notes, or an empty VIN if there is none.
+func (v VehicleRecord) VIN() VIN {
+	value, _ := v.Attribute("VIN")
+
+	return ParseVIN(value)
+}
@@ -0,0 +1,29 @@
+package roadtrip
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseCSVFloat parses the numeric value of a cell whose decimal separator
+// has already been normalized. Surrounding space is ignored and an empty cell
+// is zero.
+func parseCSVFloat(data []byte) (float64, error) {
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(text, 64)
+}
+
+// formatQuantity returns value followed by its unit, such as "621 mi", or
+// only the value when the unit is not known.
+func formatQuantity(value float64, unit string) string {
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if unit == "" {
+		return formatted
+	}
+
+	return formatted + " " + unit
+}
@@ -0,0 +1,101 @@
+package roadtrip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// A RawSection holds the contents of a section of a Road Trip data file which
+// this package does not recognize, so that sections added by newer releases
+// of the app can be read before they are modeled.
+type RawSection struct {
+	// Header is the section header exactly as written in the file.
+	Header string
+
+	// Columns are the column names from the section's CSV header row.
+	Columns []string
+
+	// Rows holds each data row keyed by column name.
+	Rows []map[string]string
+}
+
+// LogValue is the handler for [log.slog] to emit structured output for a
+// [RawSection] object when logging.
+func (r RawSection) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("header", r.Header),
+		slog.Int("columns", len(r.Columns)),
+		slog.Int("rows", len(r.Rows)),
+	)
+}
+
+// RawSections returns every section of the raw file data which does not map
+// to a canonical section header, in file order.
+func (fileData *RawFileData) RawSections() ([]RawSection, error) {
+	layout, err := fileData.layout()
+	if err != nil {
+		return nil, err
+	}
+
+	return fileData.rawSections(layout)
+}
+
+// rawSections implements [RawFileData.RawSections] for a file which has
+// already been tokenized into layout.
+func (fileData *RawFileData) rawSections(layout fileLayout) ([]RawSection, error) {
+	var sections []RawSection
+
+	for _, section := range layout.sections {
+		if section.Canonical != "" {
+			continue
+		}
+
+		raw, err := fileData.rawSection(section, layout.format.delimiters)
+		if err != nil {
+			return sections, err
+		}
+
+		sections = append(sections, raw)
+	}
+
+	return sections, nil
+}
+
+// rawSection parses the contents of a single section into a [RawSection].
+func (fileData *RawFileData) rawSection(section Section, delimiters Delimiters) (RawSection, error) {
+	raw := RawSection{Header: section.Header}
+
+	reader := csv.NewReader(bytes.NewReader((*fileData)[section.Start:section.End]))
+	reader.Comma = delimiters.Field()
+	reader.FieldsPerRecord = -1
+
+	columns, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return raw, nil
+	} else if err != nil {
+		return raw, &ParseError{Section: section.Header, Line: section.Line, Err: err}
+	}
+
+	raw.Columns = columns
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return raw, nil
+		} else if err != nil {
+			return raw, &ParseError{Section: section.Header, Line: section.HeaderLine + recordLine(reader, err), Err: err}
+		}
+
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+
+		raw.Rows = append(raw.Rows, row)
+	}
+}
@@ -4,21 +4,20 @@ package roadtrip
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"reflect"
-
-	cvslib "github.com/tiendc/go-csvlib"
+	"time"
 )
 
 const (
-	// Supported Road Trip vehicle data file version "1500,en" only.
+	// SupportedVersion is the newest Road Trip vehicle data file version
+	// known to this package. See [SupportedVersions] for the full list.
 	SupportedVersion int = 1500
-
-	// Remove erroneous header fields for VEHICLE section
-	// per Darren Stone 2024-12-09 via email.
-	RemoveErroneousHeaders = true
 )
 
 // RawFileData contains the raw contents read from a single Road Trip data
@@ -32,11 +31,60 @@ type RawSectionData []byte
 // VehicleOptions contain the options to be used when creating a new Vehicle object.
 type VehicleOptions struct {
 	Logger *slog.Logger
+
+	// Permissive allows data files with an unsupported Version or Language
+	// to be parsed anyway. The incompatibility is logged as a warning
+	// instead of being returned as an error. Without it, a file whose
+	// declared Language has no registered [LanguagePack] is rejected, so the
+	// pack is only detected from the section headers in permissive mode.
+	Permissive bool
+
+	// CollectErrors continues parsing after a row fails to decode and
+	// returns every [ParseError] in the file joined together, instead of
+	// stopping at the first one. Rows which fail are omitted.
+	CollectErrors bool
+
+	// Lenient decodes as much of the file as possible instead of failing.
+	// Rows with individual cells which cannot be parsed are kept with those
+	// fields left unset, and rows which cannot be parsed at all are dropped.
+	// Each problem is recorded in [Vehicle.Warnings] and logged as a
+	// warning. The default is strict decoding.
+	Lenient bool
+
+	// Location is the time zone in which the timestamps of the data file
+	// are interpreted. The app writes local wall clock times without a
+	// zone, so this is normally the zone the vehicle is driven in. The
+	// default is UTC.
+	Location *time.Location
+
+	// Settings is the VEHICLE record used by [Stream] to fill in the units
+	// and currencies which records leave to the vehicle's settings, as
+	// loading a [Vehicle] does. The VEHICLE section follows the others in
+	// a data file, so it is not read by [Stream] itself; it may be read
+	// first with [StreamVehicles]. Settings is ignored when loading a
+	// [Vehicle], which reads its own VEHICLE section.
+	Settings *VehicleRecord
+}
+
+// decodeMode returns how rows which fail to decode are handled.
+func (options VehicleOptions) decodeMode() decodeMode {
+	switch {
+	case options.Lenient:
+		return lenient
+	case options.CollectErrors:
+		return collectErrors
+	default:
+		return stopOnError
+	}
 }
 
 // A Vehicle holds the parsed sections contained in a Road Trip vehicle data file.
+//
+// Columns which have no corresponding record field are kept in the Extra map
+// of each record, and UnmappedColumns lists them by canonical section header.
+// Sections which this package does not recognize are kept in RawSections.
 type Vehicle struct {
-	Delimiters         string
+	Delimiters         Delimiters
 	Version            int
 	Language           string
 	Filename           string
@@ -46,44 +94,68 @@ type Vehicle struct {
 	Trips              []TripRecord        `roadtrip:"ROAD TRIPS"`
 	Tires              []TireRecord        `roadtrip:"TIRE LOG"`
 	Valuations         []ValuationRecord   `roadtrip:"VALUATIONS"`
+	Warnings           []*ParseError
+	UnmappedColumns    map[string][]string
+	RawSections        []RawSection
 	Raw                RawFileData
 	logger             *slog.Logger
+	options            VehicleOptions
 }
 
 // LogValue is the handler for [log.slog] to emit structured output for the
 // [Vehicle] object when logging.
 func (v Vehicle) LogValue() slog.Value {
-	var value slog.Value
-
-	if len(v.Vehicles) == 1 {
-		value = slog.GroupValue(
-			slog.String("name", v.Vehicles[0].Name),
-			slog.Int("version", v.Version),
-			slog.String("filename", v.Filename),
-			slog.Int("filesize", len(v.Raw)),
-			slog.Int("vehicles", len(v.Vehicles)),
-			slog.Int("fuelRecords", len(v.FuelRecords)),
-			slog.Int("mainteanceRecords", len(v.MaintenanceRecords)),
-			slog.Int("trips", len(v.Trips)),
-			slog.Int("tires", len(v.Tires)),
-			slog.Int("valuations", len(v.Valuations)),
-		)
+	attrs := []slog.Attr{
+		slog.Int("version", v.Version),
+		slog.String("filename", v.Filename),
+		slog.Int("filesize", len(v.Raw)),
+		slog.Int("vehicles", len(v.Vehicles)),
+		slog.Int("fuelRecords", len(v.FuelRecords)),
+		slog.Int("mainteanceRecords", len(v.MaintenanceRecords)),
+		slog.Int("trips", len(v.Trips)),
+		slog.Int("tires", len(v.Tires)),
+		slog.Int("valuations", len(v.Valuations)),
+		slog.Int("rawSections", len(v.RawSections)),
+		slog.Int("warnings", len(v.Warnings)),
 	}
 
-	return value
+	// Without a single VEHICLE row there is no name to report, so the
+	// reason is logged in its place.
+	info, err := v.Info()
+	if err != nil {
+		attrs = append([]slog.Attr{slog.String("vehicleError", err.Error())}, attrs...)
+	} else {
+		attrs = append([]slog.Attr{slog.String("name", info.Name)}, attrs...)
+	}
+
+	return slog.GroupValue(attrs...)
 }
 
 // NewVehicle returns a new, empty [Vehicle] object.
 func NewVehicle(options VehicleOptions) Vehicle {
 	var v Vehicle
 
-	if options.Logger == nil {
-		options.Logger = slog.New(slog.NewTextHandler(nil, nil))
-	}
+	v.setOptions(options)
+
+	return v
+}
+
+// setOptions applies the [VehicleOptions] to the [Vehicle].
+func (v *Vehicle) setOptions(options VehicleOptions) {
+	options.Logger = options.logOrDiscard()
 
 	v.logger = options.Logger
+	v.options = options
+}
 
-	return v
+// logOrDiscard returns the configured logger, or a logger which discards all
+// output if none was supplied.
+func (options VehicleOptions) logOrDiscard() *slog.Logger {
+	if options.Logger == nil {
+		return slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	return options.Logger
 }
 
 // NewVehicleFromFile returns a new [Vehicle] object populated with data read
@@ -99,14 +171,27 @@ func NewVehicleFromFile(filename string, options VehicleOptions) (Vehicle, error
 	return v, nil
 }
 
+// NewVehicleFromFS returns a new [Vehicle] object populated with data read
+// and parsed from the named file in the file system fsys.
+func NewVehicleFromFS(fsys fs.FS, name string, options VehicleOptions) (Vehicle, error) {
+	v := NewVehicle(options)
+
+	err := v.LoadFS(fsys, name)
+	if err != nil {
+		return v, err
+	}
+
+	return v, nil
+}
+
 // Each Road Trip "CSV" file is actually multiple, independent blocks of CSV
 // data delimited by two newlines and a section header string in all capital
 // letters.
 //
 // SectionHeaderList returns a slice of strings corresponding to each of the
-// section headers expected in the Road Trip vehicle data file. Currently this
-// package only supports Language "en" (see known issues in the README.md
-// file).
+// canonical (English) section headers expected in the Road Trip vehicle data
+// file. Files written in other languages are mapped onto these headers by a
+// [LanguagePack].
 //
 // This list is built by inspecting the `roadtrip` struct tags present in the
 // [Vehicle] struct definition.
@@ -146,32 +231,16 @@ func SectionHeaderForTarget(target any) (string, error) {
 }
 
 // GetSectionContents evaluates the raw content from a Road Trip data file and extracts only
-// the single section block identified by the supplied section header string value.
+// the single section block identified by the supplied canonical section header string value.
+// The section header line itself is not included. A section which is absent from the file
+// yields empty contents.
 func (fileData *RawFileData) GetSectionContents(sectionHeader string) RawSectionData {
-	sectionStart := make(map[string]int)
-
-	dataBytes := reflect.ValueOf(*fileData).Bytes()
-
-	for _, element := range SectionHeaderList() {
-		i := bytes.Index(dataBytes, []byte(element))
-		sectionStart[element] = i
-	}
-
-	startPosition := sectionStart[sectionHeader]
-	endPosition := len(dataBytes)
-
-	for _, e := range sectionStart {
-		if e > startPosition && e < endPosition {
-			endPosition = e - 1
-		}
+	section, ok := fileData.Section(sectionHeader)
+	if !ok {
+		return RawSectionData{}
 	}
 
-	// Don't include the section header line in the outbuf
-	startPosition = startPosition + len(sectionHeader) + 1
-
-	outbuf := dataBytes[startPosition:endPosition]
-
-	return outbuf
+	return RawSectionData((*fileData)[section.Start:section.End])
 }
 
 // UnmarshalRoadtripSection takes the raw contents of a Road Trip vehicle data
@@ -180,20 +249,149 @@ func (fileData *RawFileData) GetSectionContents(sectionHeader string) RawSection
 //
 // This relies on an accurate struct tag on the [Vehicle] field in question
 // which instructs the function on which section header line to look for.
+//
+// Field splitting and numeric parsing honor the [Delimiters] declared in the
+// file info line, and localized column names are translated by the file's
+// [LanguagePack]. Rows which fail to parse are reported as a [ParseError].
 func (fileData *RawFileData) UnmarshalRoadtripSection(target any) error {
-	header, err := SectionHeaderForTarget(target)
+	layout, err := fileData.layout()
 	if err != nil {
 		return err
 	}
 
-	sectionData := fileData.GetSectionContents(header)
+	_, err = fileData.unmarshalSection(layout, target, VehicleOptions{})
+
+	return err
+}
+
+// unmarshalSection implements [RawFileData.UnmarshalRoadtripSection] for a
+// file which has already been tokenized into layout, handling rows which fail
+// to decode according to the decode mode of options. It also returns the
+// names of the section's columns which have no corresponding record field.
+func (fileData *RawFileData) unmarshalSection(layout fileLayout, target any, options VehicleOptions) ([]string, error) {
+	header, err := SectionHeaderForTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	section, ok := findSection(layout.sections, header)
+	if !ok {
+		return nil, nil
+	}
+
+	sectionData := (*fileData)[section.Start:section.End]
+
+	decoder, err := newSectionDecoder(bytes.NewReader(sectionData), section, target, layout.format)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	decoder.location = options.Location
+
+	return decoder.unmappedColumnNames(), decoder.decodeAll(target, options.decodeMode())
+}
+
+// warn records each [ParseError] in err as a warning on the [Vehicle] and
+// logs it.
+func (v *Vehicle) warn(err error) {
+	v.Warnings = append(v.Warnings, logWarnings(v.logger, v.Filename, err)...)
+}
+
+// logWarnings logs each [ParseError] in err as a warning and returns them.
+func logWarnings(logger *slog.Logger, filename string, err error) []*ParseError {
+	list := parseErrorList(err)
+
+	for _, parseErr := range list {
+		logger.Warn("Skipped invalid Road Trip data",
+			"filename", filename,
+			"section", parseErr.Section,
+			"line", parseErr.Line,
+			"column", parseErr.Column,
+			"value", parseErr.Value,
+			"error", parseErr.Err,
+		)
+	}
+
+	return list
+}
+
+// unmarshalSections parses each section target and any unrecognized sections
+// from the raw file data, handling errors according to the decode mode.
+func (v *Vehicle) unmarshalSections(data RawFileData, targets []any) error {
+	var errs []error
+
+	// handle applies the decode mode to a section error and returns it only
+	// when parsing should stop.
+	handle := func(err error) error {
+		switch v.options.decodeMode() {
+		case lenient:
+			v.warn(err)
+		case collectErrors:
+			errs = append(errs, err)
+		default:
+			return err
+		}
 
-	_, err = cvslib.Unmarshal(sectionData, target)
+		return nil
+	}
+
+	v.Warnings = nil
+	v.UnmappedColumns = make(map[string][]string)
+
+	layout, err := data.layout()
 	if err != nil {
 		return err
 	}
 
-	return nil
+	for _, target := range targets {
+		var unmapped []string
+
+		unmapped, err = data.unmarshalSection(layout, target, v.options)
+		if len(unmapped) > 0 {
+			header, _ := SectionHeaderForTarget(target)
+			v.UnmappedColumns[header] = unmapped
+
+			v.logger.Info("Unmapped Road Trip columns",
+				"filename", v.Filename,
+				"section", header,
+				"columns", unmapped,
+			)
+		}
+
+		if err != nil && handle(err) != nil {
+			return err
+		}
+	}
+
+	v.applyVehicleSettings(targets)
+
+	rawSections, err := data.rawSections(layout)
+	if err != nil && handle(err) != nil {
+		return err
+	}
+
+	v.RawSections = rawSections
+
+	return errors.Join(errs...)
+}
+
+// applyVehicleSettings fills in record details which depend on the settings in
+// the VEHICLE section once every section has been parsed.
+func (v *Vehicle) applyVehicleSettings(targets []any) {
+	info, err := v.Info()
+	if err != nil {
+		return
+	}
+
+	for _, target := range targets {
+		records := reflect.ValueOf(target).Elem()
+
+		for i := range records.Len() {
+			info.applySettings(records.Index(i).Addr().Interface())
+		}
+	}
 }
 
 // SetLogger optionally sets the [Vehicle] logger for internal package
@@ -213,11 +411,22 @@ func (v *Vehicle) LoadFile(filename string) error {
 
 	v.Filename = filename
 
-	if RemoveErroneousHeaders {
-		omitHeaders := []byte(",Tank 1 Type,Tank 2 Type,Tank 2 Units")
-		buf = bytes.Replace(buf, omitHeaders, []byte{}, 1)
+	return v.UnmarshalRoadtrip(buf)
+}
+
+// LoadFS reads and parses a file from the file system fsys into the
+// [Vehicle] object. It allows data files to be read from an [embed.FS] or
+// any other [fs.FS] implementation.
+func (v *Vehicle) LoadFS(fsys fs.FS, name string) error {
+	var buf RawFileData
+
+	buf, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
 	}
 
+	v.Filename = name
+
 	return v.UnmarshalRoadtrip(buf)
 }
 
@@ -228,6 +437,28 @@ func (v *Vehicle) UnmarshalRoadtrip(data RawFileData) error {
 
 	var err error
 
+	v.Delimiters, err = data.Delimiters()
+	if err != nil {
+		return err
+	}
+
+	v.Version, v.Language, err = data.VersionInfo()
+	if err != nil {
+		return err
+	}
+
+	err = CheckCompatibility(v.Version, v.Language)
+	if err != nil {
+		if !v.options.Permissive {
+			return err
+		}
+
+		v.logger.Warn("Parsing unsupported Road Trip vehicle data file",
+			"filename", v.Filename,
+			"error", err,
+		)
+	}
+
 	// This seems ripe for future improvement, it should be possible
 	// to generate the targets array by reflecting through v and finding
 	// the correct pointers to append.
@@ -239,11 +470,9 @@ func (v *Vehicle) UnmarshalRoadtrip(data RawFileData) error {
 	targets = append(targets, &v.Tires)
 	targets = append(targets, &v.Valuations)
 
-	for _, target := range targets {
-		err = data.UnmarshalRoadtripSection(target)
-		if err != nil {
-			return fmt.Errorf("unable to parse %s: %w", target, err)
-		}
+	err = v.unmarshalSections(data, targets)
+	if err != nil {
+		return err
 	}
 
 	v.logger.Debug("Loaded Road Trip vehicle data file",
@@ -1 +1,1642 @@
 package roadtrip_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/nugget/roadtrip-go/roadtrip"
+)
+
+const (
+	exampleFile  = "../examples/CSV/Example Vehicle.csv"
+	europeanFile = "testdata/European Vehicle.csv"
+	frenchFile   = "testdata/Synthetic French Vehicle.csv"
+
+	// fixtureName is the name of the modified data file in fixtureFS.
+	fixtureName = "vehicle.csv"
+)
+
+// syntheticFrenchPack is a made-up French language pack used only to test
+// the language pack machinery. Its strings, and those of frenchFile, were
+// written by hand and not taken from a real French export of the app.
+var syntheticFrenchPack = roadtrip.LanguagePack{
+	Language: "fr",
+	Sections: map[string]string{
+		"PLEINS":      "FUEL RECORDS",
+		"ENTRETIEN":   "MAINTENANCE RECORDS",
+		"VOYAGES":     "ROAD TRIPS",
+		"VÉHICULE":    "VEHICLE",
+		"PNEUS":       "TIRE LOG",
+		"ÉVALUATIONS": "VALUATIONS",
+	},
+	Columns: map[string]string{
+		"Langue":                           "Language",
+		"Odomètre (mi)":                    "Odometer (mi)",
+		"Distance du trajet":               "Trip Distance",
+		"Quantité":                         "Fill Amount",
+		"Unités":                           "Fill Units",
+		"Prix unitaire":                    "Price per Unit",
+		"Prix total":                       "Total Price",
+		"Plein partiel":                    "Partial Fill",
+		"Consommation":                     "MPG",
+		"Indice d'octane":                  "Octane",
+		"Lieu":                             "Location",
+		"Paiement":                         "Payment",
+		"Réinitialisation":                 "Reset",
+		"Catégories":                       "Categories",
+		"Indicateurs":                      "Flags",
+		"Code devise":                      "Currency Code",
+		"Taux de change":                   "Currency Rate",
+		"Ordinateur de bord consommation":  "Trip Comp Fuel Economy",
+		"Ordinateur de bord vitesse moy.":  "Trip Comp Avg. Speed",
+		"Ordinateur de bord température":   "Trip Comp Temperature",
+		"Ordinateur de bord durée":         "Trip Comp Drive Time",
+		"Numéro de réservoir":              "Tank Number",
+		"Odomètre (mi.)":                   "Odometer (mi.)",
+		"Coût":                             "Cost",
+		"Sous-type":                        "Subtype",
+		"Intervalle de rappel":             "Reminder Interval",
+		"Distance de rappel":               "Reminder Distance",
+		"Intervalle de notification":       "Notification Interval",
+		"Distance de notification":         "Notification Distance",
+		"Nom":                              "Name",
+		"Date de début":                    "Start Date",
+		"Odomètre de début (mi.)":          "Start Odometer (mi.)",
+		"Date de fin":                      "End Date",
+		"Odomètre de fin":                  "End Odometer",
+		"Odomètre":                         "Odometer",
+		"Unité":                            "Units",
+		"Capacité du réservoir":            "Tank Capacity",
+		"Unités du réservoir":              "Tank Units",
+		"Devise locale":                    "Home Currency",
+		"Unités de carburant":              "FuelUnits",
+		"Unités ordinateur de bord":        "TripComp Units",
+		"Vitesse ordinateur de bord":       "TripComp Speed",
+		"Température ordinateur de bord":   "TripComp Temperature",
+		"Durée ordinateur de bord activée": "TripComp Time Enabled",
+		"Décalage de l'odomètre":           "Odometer Shift",
+		"Type du réservoir 1":              "Tank 1 Type",
+		"Type du réservoir 2":              "Tank 2 Type",
+		"Unités du réservoir 2":            "Tank 2 Units",
+		"Taille":                           "Size",
+		"Correction de taille":             "Size Correction",
+		"Âge":                              "Age",
+		"Prix":                             "Price",
+	},
+}
+
+func init() {
+	if err := roadtrip.RegisterLanguagePack(syntheticFrenchPack); err != nil {
+		panic(err)
+	}
+}
+
+// readFixture returns the contents of file with the replacements applied as
+// by replaceOnce.
+func readFixture(t *testing.T, file string, replacements ...string) []byte {
+	t.Helper()
+
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return replaceOnce(t, buf, replacements...)
+}
+
+// replaceOnce returns a copy of buf with the first instance of each old
+// string replaced by the new string which follows it. The test fails when an
+// old string is missing, so that a fixture cannot silently stop matching the
+// file it modifies.
+func replaceOnce(t *testing.T, buf []byte, replacements ...string) []byte {
+	t.Helper()
+
+	if len(replacements)%2 != 0 {
+		t.Fatalf("replaceOnce() given %d strings, want old and new pairs", len(replacements))
+	}
+
+	for pair := range slices.Chunk(replacements, 2) {
+		if !bytes.Contains(buf, []byte(pair[0])) {
+			t.Fatalf("fixture does not contain %q", pair[0])
+		}
+
+		buf = bytes.Replace(buf, []byte(pair[0]), []byte(pair[1]), 1)
+	}
+
+	return buf
+}
+
+// replaceAll returns a copy of buf with every instance of old replaced by
+// replacement. The test fails when old is missing.
+func replaceAll(t *testing.T, buf []byte, old, replacement string) []byte {
+	t.Helper()
+
+	if !bytes.Contains(buf, []byte(old)) {
+		t.Fatalf("fixture does not contain %q", old)
+	}
+
+	return bytes.ReplaceAll(buf, []byte(old), []byte(replacement))
+}
+
+// fixtureFS returns a file system holding data as fixtureName.
+func fixtureFS(data []byte) fstest.MapFS {
+	return fstest.MapFS{fixtureName: {Data: data}}
+}
+
+func TestParseDelimiters(t *testing.T) {
+	tests := []struct {
+		line    string
+		field   rune
+		decimal rune
+		wantErr bool
+	}{
+		{line: `ROAD TRIP CSV ",."`, field: ',', decimal: '.'},
+		{line: `ROAD TRIP CSV ";,"`, field: ';', decimal: ','},
+		{line: "\ufeffROAD TRIP CSV \";,\"\r", field: ';', decimal: ','},
+		{line: `ROAD TRIP CSV ""`, wantErr: true},
+		{line: `Version,Language`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		d, err := roadtrip.ParseDelimiters(tt.line)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDelimiters(%q) expected error, got %q", tt.line, d)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseDelimiters(%q) unexpected error: %v", tt.line, err)
+			continue
+		}
+
+		if d.Field() != tt.field || d.Decimal() != tt.decimal {
+			t.Errorf("ParseDelimiters(%q) = %q/%q, want %q/%q",
+				tt.line, d.Field(), d.Decimal(), tt.field, tt.decimal)
+		}
+	}
+}
+
+func TestLoadExampleFile(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatalf("unable to load %s: %v", exampleFile, err)
+	}
+
+	if v.Delimiters != roadtrip.DefaultDelimiters {
+		t.Errorf("Delimiters = %q, want %q", v.Delimiters, roadtrip.DefaultDelimiters)
+	}
+
+	if got := len(v.FuelRecords); got != 107 {
+		t.Errorf("len(FuelRecords) = %d, want 107", got)
+	}
+
+	if got := v.FuelRecords[1].PricePerUnit; got != (roadtrip.Money{Amount: 4.4993, Currency: "USD"}) {
+		t.Errorf("FuelRecords[1].PricePerUnit = %v, want 4.4993", got)
+	}
+}
+
+func TestLoadEuropeanFile(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(europeanFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatalf("unable to load %s: %v", europeanFile, err)
+	}
+
+	if v.Delimiters.Field() != ';' || v.Delimiters.Decimal() != ',' {
+		t.Errorf("Delimiters = %q, want \";,\"", v.Delimiters)
+	}
+
+	if got := len(v.FuelRecords); got != 2 {
+		t.Fatalf("len(FuelRecords) = %d, want 2", got)
+	}
+
+	f := v.FuelRecords[1]
+	if f.FillAmount != (roadtrip.Volume{Value: 41.25, Unit: roadtrip.Liters}) ||
+		f.PricePerUnit != (roadtrip.Money{Amount: 1.849, Currency: "EUR"}) || f.Latitude != 53.551086 {
+		t.Errorf("FuelRecords[1] decimals parsed incorrectly: %+v", f)
+	}
+
+	if got := v.MaintenanceRecords[0].Cost; got.Amount != 89.9 {
+		t.Errorf("MaintenanceRecords[0].Cost = %v, want 89.9", got)
+	}
+
+	if got := v.Vehicles[0].TankCapacity; got != (roadtrip.Volume{Value: 55.5, Unit: roadtrip.Liters}) {
+		t.Errorf("Vehicles[0].TankCapacity = %v, want 55.5", got)
+	}
+}
+
+func TestVersionCompatibility(t *testing.T) {
+	buf := readFixture(t, exampleFile)
+
+	v := roadtrip.NewVehicle(roadtrip.VehicleOptions{})
+
+	err := v.UnmarshalRoadtrip(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v.Version != roadtrip.SupportedVersion || v.Language != "en" {
+		t.Errorf("Version,Language = %d,%s, want %d,en", v.Version, v.Language, roadtrip.SupportedVersion)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr error
+	}{
+		{name: "version", header: "Version,Language\n9999,en", wantErr: roadtrip.ErrUnsupportedVersion},
+		{name: "language", header: "Version,Language\n1500,xx", wantErr: roadtrip.ErrUnsupportedLanguage},
+		{name: "missing", header: "Nothing,Here\n1,2", wantErr: roadtrip.ErrMissingVersion},
+	}
+
+	for _, tt := range tests {
+		data := replaceOnce(t, buf, "Version,Language\n1500,en", tt.header)
+
+		strict := roadtrip.NewVehicle(roadtrip.VehicleOptions{})
+		if err := strict.UnmarshalRoadtrip(data); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: strict error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+
+		for _, err := range roadtrip.StreamFuelRecords(bytes.NewReader(data), roadtrip.VehicleOptions{}) {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: strict stream error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+
+			break
+		}
+
+		if errors.Is(tt.wantErr, roadtrip.ErrMissingVersion) {
+			continue
+		}
+
+		permissive := roadtrip.NewVehicle(roadtrip.VehicleOptions{Permissive: true})
+		if err := permissive.UnmarshalRoadtrip(data); err != nil {
+			t.Errorf("%s: permissive error = %v, want nil", tt.name, err)
+		}
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	if err := roadtrip.CheckCompatibility(roadtrip.SupportedVersion, "en"); err != nil {
+		t.Errorf("CheckCompatibility(%d, en) = %v, want nil", roadtrip.SupportedVersion, err)
+	}
+
+	if err := roadtrip.CheckCompatibility(roadtrip.SupportedVersion, "xx"); !errors.Is(err, roadtrip.ErrUnsupportedLanguage) {
+		t.Errorf("CheckCompatibility(%d, xx) = %v, want %v", roadtrip.SupportedVersion, err, roadtrip.ErrUnsupportedLanguage)
+	}
+}
+
+func TestLanguagePacks(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(frenchFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatalf("unable to load %s: %v", frenchFile, err)
+	}
+
+	if v.Language != "fr" {
+		t.Errorf("Language = %q, want fr", v.Language)
+	}
+
+	if len(v.FuelRecords) != 2 || v.FuelRecords[1].Location != "Shell Hamburg" {
+		t.Errorf("FuelRecords not mapped from French columns: %+v", v.FuelRecords)
+	}
+
+	if len(v.Vehicles) != 1 || v.Vehicles[0].HomeCurrency != "EUR" {
+		t.Errorf("Vehicles not mapped from French columns: %+v", v.Vehicles)
+	}
+
+	// A file with an unregistered Language is detected by its headers.
+	buf := readFixture(t, frenchFile)
+
+	data := roadtrip.RawFileData(replaceOnce(t, buf, "1500;fr", "1500;fr-CA"))
+
+	pack, err := data.LanguagePack()
+	if err != nil || pack.Language != "fr" {
+		t.Errorf("LanguagePack() = %q, %v, want fr", pack.Language, err)
+	}
+
+	// Registered packs may remap the section headers.
+	custom := roadtrip.LanguagePack{
+		Language: "x-test",
+		Sections: map[string]string{"CARBURANT": "FUEL RECORDS"},
+	}
+
+	if err := roadtrip.RegisterLanguagePack(custom); err != nil {
+		t.Fatal(err)
+	}
+
+	pack, ok := roadtrip.LookupLanguagePack("x-test")
+	if !ok || pack.SectionHeader("FUEL RECORDS") != "CARBURANT" || pack.SectionHeader("VEHICLE") != "VEHICLE" {
+		t.Errorf("LookupLanguagePack(x-test) = %+v, %v", pack, ok)
+	}
+
+	// Every alias of a header or column is recognized, not just one.
+	alias := roadtrip.LanguagePack{
+		Language: "x-alias",
+		Sections: map[string]string{"CARBURANT": "FUEL RECORDS", "PLEINS": "FUEL RECORDS"},
+		Columns:  map[string]string{"Compteur (mi)": "Odometer (mi)", "Odomètre (mi)": "Odometer (mi)"},
+	}
+
+	if err := roadtrip.RegisterLanguagePack(alias); err != nil {
+		t.Fatal(err)
+	}
+
+	example := readFixture(t, exampleFile,
+		"1500,en", "1500,x-alias",
+		"\nFUEL RECORDS\nOdometer (mi),", "\nPLEINS\nOdomètre (mi),",
+	)
+
+	for range 20 {
+		v, err := roadtrip.NewVehicleFromFS(fixtureFS(example), fixtureName,
+			roadtrip.VehicleOptions{})
+		if err != nil || len(v.FuelRecords) != 107 || v.FuelRecords[1].Odometer.Value != 621 {
+			t.Fatalf("aliased file = %d fuel records, %v, want 107 with the second at 621 mi", len(v.FuelRecords), err)
+		}
+
+		streamed := 0
+
+		for _, err := range roadtrip.StreamFuelRecords(bytes.NewReader(example), roadtrip.VehicleOptions{}) {
+			if err != nil {
+				t.Fatalf("StreamFuelRecords(aliased file) unexpected error: %v", err)
+			}
+
+			streamed++
+		}
+
+		if streamed != 107 {
+			t.Fatalf("StreamFuelRecords(aliased file) = %d records, want 107", streamed)
+		}
+	}
+
+	if got := alias.SectionHeader("FUEL RECORDS"); got != "CARBURANT" {
+		t.Errorf("SectionHeader(FUEL RECORDS) = %q, want the first alias CARBURANT", got)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	buf := readFixture(t, exampleFile)
+
+	var v roadtrip.Vehicle
+
+	err := roadtrip.NewDecoder(bytes.NewReader(buf), roadtrip.VehicleOptions{}).Decode(&v)
+	if err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+
+	if len(v.FuelRecords) != 107 || len(v.Vehicles) != 1 {
+		t.Errorf("Decode() parsed %d fuel records and %d vehicles, want 107 and 1",
+			len(v.FuelRecords), len(v.Vehicles))
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	buf := readFixture(t, europeanFile)
+
+	fsys := fstest.MapFS{"garage/golf.csv": &fstest.MapFile{Data: buf}}
+
+	v, err := roadtrip.NewVehicleFromFS(fsys, "garage/golf.csv", roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatalf("NewVehicleFromFS() unexpected error: %v", err)
+	}
+
+	if v.Filename != "garage/golf.csv" || len(v.FuelRecords) != 2 {
+		t.Errorf("NewVehicleFromFS() = %s with %d fuel records", v.Filename, len(v.FuelRecords))
+	}
+
+	_, err = roadtrip.NewVehicleFromFS(fsys, "missing.csv", roadtrip.VehicleOptions{})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("NewVehicleFromFS(missing) error = %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestSections(t *testing.T) {
+	buf := readFixture(t, exampleFile)
+
+	data := roadtrip.RawFileData(buf)
+
+	sections := data.Sections()
+	if len(sections) != 6 {
+		t.Fatalf("Sections() found %d sections, want 6", len(sections))
+	}
+
+	fuel := sections[0]
+	if fuel.Canonical != "FUEL RECORDS" || fuel.HeaderLine != 6 || fuel.Line != 7 {
+		t.Errorf("Sections()[0] = %+v, want FUEL RECORDS at line 6", fuel)
+	}
+
+	if !bytes.HasPrefix(buf[fuel.Start:fuel.End], []byte("Odometer (mi),")) ||
+		!bytes.HasSuffix(buf[fuel.Start:fuel.End], []byte(",112,,,10,,0\n")) {
+		t.Errorf("Sections()[0] offsets %d-%d do not frame the fuel records", fuel.Start, fuel.End)
+	}
+
+	// Header text inside a quoted multi-line note must not start a section.
+	notes := replaceOnce(t, buf, "Oil Type:\n\nNotes:\n", "Oil Type:\n\nTIRE LOG\nVALUATIONS\n")
+
+	// A missing section is treated as empty rather than corrupting others.
+	valuations := bytes.Index(notes, []byte("\n\nVALUATIONS\n"))
+	notes = notes[:valuations+1]
+
+	v := roadtrip.NewVehicle(roadtrip.VehicleOptions{})
+	if err := roadtrip.NewDecoder(bytes.NewReader(notes), roadtrip.VehicleOptions{}).Decode(&v); err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+
+	if len(v.Vehicles) != 1 || !strings.Contains(v.Vehicles[0].Notes, "TIRE LOG") {
+		t.Errorf("VEHICLE notes were split at an embedded header: %+v", v.Vehicles)
+	}
+
+	if len(v.Tires) != 1 || len(v.Valuations) != 0 {
+		t.Errorf("got %d tires and %d valuations, want 1 and 0", len(v.Tires), len(v.Valuations))
+	}
+
+	// A stray quote in a corrupted row must not hide the sections after it.
+	corrupt := roadtrip.RawFileData(replaceOnce(t, buf, "621,284,", `621,2"84,`))
+	if n := len(corrupt.Sections()); n != 6 {
+		t.Errorf("Sections() after a stray quote found %d sections, want 6", n)
+	}
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(corrupt), fixtureName,
+		roadtrip.VehicleOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("lenient decoding after a stray quote failed: %v", err)
+	}
+
+	if len(v.Warnings) != 1 || v.FuelRecords[1].Odometer.Value != 931 {
+		t.Errorf("corrupted row was not skipped with a warning: %v", v.Warnings)
+	}
+
+	if len(v.Vehicles) != 1 || len(v.Tires) != 1 {
+		t.Errorf("after a stray quote got %d vehicles and %d tires, want 1 and 1", len(v.Vehicles), len(v.Tires))
+	}
+}
+
+func TestStream(t *testing.T) {
+	f, err := os.Open(exampleFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var (
+		count int
+		total float64
+	)
+
+	for fuel, err := range roadtrip.StreamFuelRecords(f, roadtrip.VehicleOptions{}) {
+		if err != nil {
+			t.Fatalf("StreamFuelRecords() unexpected error: %v", err)
+		}
+
+		count++
+		total += fuel.FillAmount.Value
+	}
+
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want float64
+	for _, fuel := range v.FuelRecords {
+		want += fuel.FillAmount.Value
+	}
+
+	if count != len(v.FuelRecords) || total != want {
+		t.Errorf("StreamFuelRecords() = %d records totalling %v, want %d totalling %v",
+			count, total, len(v.FuelRecords), want)
+	}
+
+	// Stopping early must be honored.
+	buf := readFixture(t, exampleFile)
+
+	count = 0
+	for range roadtrip.StreamMaintenanceRecords(bytes.NewReader(buf), roadtrip.VehicleOptions{}) {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("StreamMaintenanceRecords() stopped after %d records, want 2", count)
+	}
+
+	// A section spanning several batches is streamed in full, with errors
+	// reported at their own line.
+	start := bytes.Index(buf, []byte("\n337,")) + 1
+	end := start + bytes.IndexByte(buf[start:], '\n') + 1
+	row := buf[start:end]
+	long := slices.Concat(buf[:end], bytes.Repeat(row, 399),
+		replaceOnce(t, row, "3.7992", "bogus"), bytes.Repeat(row, 200), buf[end:])
+
+	var errs []error
+
+	count = 0
+	for _, err := range roadtrip.StreamFuelRecords(bytes.NewReader(long), roadtrip.VehicleOptions{}) {
+		count++
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if count != len(v.FuelRecords)+600 {
+		t.Errorf("StreamFuelRecords() yielded %d records, want %d", count, len(v.FuelRecords)+600)
+	}
+
+	var parseErr *roadtrip.ParseError
+	if len(errs) != 1 || !errors.As(errs[0], &parseErr) || parseErr.Line != 408 || parseErr.Value != "bogus" {
+		t.Errorf("StreamFuelRecords() errors = %v, want 'bogus' on line 408", errs)
+	}
+
+	for vehicle, err := range roadtrip.StreamVehicles(bytes.NewReader(buf), roadtrip.VehicleOptions{}) {
+		if err != nil || vehicle.Name != "2022 Portofino M" || vehicle.HomeCurrency != "USD" {
+			t.Errorf("StreamVehicles() = %+v, %v", vehicle, err)
+		}
+	}
+
+	for valuation, err := range roadtrip.StreamValuations(bytes.NewReader(buf), roadtrip.VehicleOptions{}) {
+		if err != nil || valuation.Price.Amount != 352334 {
+			t.Errorf("StreamValuations() = %+v, %v", valuation, err)
+		}
+	}
+
+	// Units left to the vehicle's settings are only filled in with Settings.
+	settings := v.Vehicles[0]
+
+	for _, options := range []roadtrip.VehicleOptions{{}, {Settings: &settings}} {
+		var streamed []roadtrip.FuelRecord
+
+		for fuel, err := range roadtrip.StreamFuelRecords(bytes.NewReader(buf), options) {
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			streamed = append(streamed, fuel)
+		}
+
+		got, want := streamed[1], v.FuelRecords[1]
+
+		switch {
+		case options.Settings == nil && (got.MPG.Unit != "" || got.TotalPrice.Currency != ""):
+			t.Errorf("streamed without Settings: MPG %v, TotalPrice %v, want no unit or currency", got.MPG, got.TotalPrice)
+		case options.Settings != nil && (got.Odometer != want.Odometer || got.MPG != want.MPG ||
+			got.FillAmount != want.FillAmount || got.TotalPrice != want.TotalPrice):
+			t.Errorf("streamed with Settings: %v, %v, %v, %v, want %v, %v, %v, %v",
+				got.Odometer, got.MPG, got.FillAmount, got.TotalPrice,
+				want.Odometer, want.MPG, want.FillAmount, want.TotalPrice)
+		}
+	}
+
+	french, err := os.Open(frenchFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer french.Close()
+
+	var amounts []float64
+	for fuel, err := range roadtrip.StreamFuelRecords(french, roadtrip.VehicleOptions{}) {
+		if err != nil {
+			t.Fatalf("StreamFuelRecords(%s) unexpected error: %v", frenchFile, err)
+		}
+
+		amounts = append(amounts, fuel.FillAmount.Value)
+	}
+
+	if !slices.Equal(amounts, []float64{45.5, 41.25}) {
+		t.Errorf("StreamFuelRecords(%s) fill amounts = %v, want [45.5 41.25]", frenchFile, amounts)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	buf := readFixture(t, exampleFile,
+		"4.4993", "bogus",
+		"3.4881", "worse",
+	)
+
+	v := roadtrip.NewVehicle(roadtrip.VehicleOptions{})
+
+	err := v.LoadFS(fixtureFS(buf), fixtureName)
+
+	var parseErr *roadtrip.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("error = %v, want *ParseError", err)
+	}
+
+	if parseErr.Section != "FUEL RECORDS" || parseErr.Line != 9 ||
+		parseErr.Column != "Price per Unit" || parseErr.Value != "bogus" {
+		t.Errorf("ParseError = %+v, want FUEL RECORDS line 9 'Price per Unit' 'bogus'", parseErr)
+	}
+
+	v = roadtrip.NewVehicle(roadtrip.VehicleOptions{CollectErrors: true})
+
+	err = v.LoadFS(fixtureFS(buf), fixtureName)
+	if err == nil {
+		t.Fatal("expected collected errors")
+	}
+
+	if n := strings.Count(err.Error(), "column 'Price per Unit'"); n != 2 {
+		t.Errorf("collected %d Price per Unit errors, want 2: %v", n, err)
+	}
+
+	if len(v.FuelRecords) != 105 {
+		t.Errorf("%d fuel records with CollectErrors, want 105", len(v.FuelRecords))
+	}
+
+	if len(v.Vehicles) != 1 {
+		t.Errorf("%d vehicle records with CollectErrors, want 1", len(v.Vehicles))
+	}
+
+	// A row which the CSV reader cannot parse is reported at its own line.
+	malformed := replaceOnce(t, buf, "621,284,", `6"21,284,`)
+	fsys := fixtureFS(malformed)
+
+	for _, options := range []roadtrip.VehicleOptions{{}, {CollectErrors: true}, {Lenient: true}} {
+		v = roadtrip.NewVehicle(options)
+
+		err = v.LoadFS(fsys, "vehicle.csv")
+		if options.Lenient && len(v.Warnings) > 0 {
+			err = v.Warnings[0]
+		}
+
+		if !errors.As(err, &parseErr) || parseErr.Line != 9 || !errors.Is(err, csv.ErrBareQuote) {
+			t.Errorf("%+v: error = %v, want bare quote on FUEL RECORDS line 9", options, err)
+		}
+	}
+
+	var streamErr error
+
+	for _, err := range roadtrip.StreamFuelRecords(bytes.NewReader(malformed), roadtrip.VehicleOptions{}) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+	}
+
+	if !errors.As(streamErr, &parseErr) || parseErr.Line != 9 {
+		t.Errorf("StreamFuelRecords() error = %v, want FUEL RECORDS line 9", streamErr)
+	}
+}
+
+func TestLenient(t *testing.T) {
+	buf := readFixture(t, exampleFile,
+		"4.4993", "bogus",
+		"931,310,", "931,310,extra,",
+	)
+
+	fsys := fixtureFS(buf)
+
+	if _, err := roadtrip.NewVehicleFromFS(fsys, "vehicle.csv", roadtrip.VehicleOptions{}); err == nil {
+		t.Error("strict decoding succeeded, want error")
+	}
+
+	v, err := roadtrip.NewVehicleFromFS(fsys, "vehicle.csv", roadtrip.VehicleOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("lenient decoding failed: %v", err)
+	}
+
+	if len(v.FuelRecords) != 106 {
+		t.Errorf("%d fuel records, want 106", len(v.FuelRecords))
+	}
+
+	if r := v.FuelRecords[1]; r.Odometer.Value != 621 || r.PricePerUnit.Amount != 0 || r.TotalPrice.Amount != 71.3 {
+		t.Errorf("partial record = %+v, want Odometer 621, PricePerUnit 0, TotalPrice 71.3", r)
+	}
+
+	if len(v.Warnings) != 2 {
+		t.Fatalf("%d warnings, want 2: %v", len(v.Warnings), v.Warnings)
+	}
+
+	if w := v.Warnings[0]; w.Line != 9 || w.Column != "Price per Unit" {
+		t.Errorf("warning = %v, want line 9 column 'Price per Unit'", w)
+	}
+
+	if w := v.Warnings[1]; w.Line != 10 || !errors.Is(w, csv.ErrFieldCount) {
+		t.Errorf("warning = %v, want line 10 field count error", w)
+	}
+
+	var streamed int
+
+	for _, err := range roadtrip.StreamFuelRecords(bytes.NewReader(buf), roadtrip.VehicleOptions{Lenient: true}) {
+		if err != nil {
+			t.Fatalf("lenient stream error: %v", err)
+		}
+
+		streamed++
+	}
+
+	if streamed != 106 {
+		t.Errorf("streamed %d fuel records, want 106", streamed)
+	}
+}
+
+func TestUnmappedColumns(t *testing.T) {
+	buf := readFixture(t, exampleFile,
+		"Price,Notes,Flags\n", "Price,Source,Notes,Flags\n",
+		"352334,,0", "352334,Dealer,,0",
+	)
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := v.UnmappedColumns["VALUATIONS"]; !slices.Equal(got, []string{"Source"}) {
+		t.Errorf("UnmappedColumns[VALUATIONS] = %v, want [Source]", got)
+	}
+
+	if len(v.UnmappedColumns) != 1 {
+		t.Errorf("UnmappedColumns = %v, want only VALUATIONS", v.UnmappedColumns)
+	}
+
+	if got := v.Valuations[0]; got.Extra["Source"] != "Dealer" || got.Price.Amount != 352334 || got.Flags != 0 {
+		t.Errorf("valuation = %+v, want Extra Source Dealer with other fields intact", got)
+	}
+
+	if v.FuelRecords[0].Extra != nil {
+		t.Errorf("fuel Extra = %v, want nil", v.FuelRecords[0].Extra)
+	}
+
+	for r, err := range roadtrip.StreamValuations(bytes.NewReader(buf), roadtrip.VehicleOptions{}) {
+		if err != nil || r.Extra["Source"] != "Dealer" {
+			t.Errorf("streamed valuation = %+v, %v, want Extra Source Dealer", r, err)
+		}
+	}
+}
+
+func TestRawSections(t *testing.T) {
+	buf := readFixture(t, exampleFile,
+		"\nVALUATIONS\n", "\nCHARGING SESSIONS\nDate,Energy (kWh)\n\"2024-1-1\",12.5\n\"2024-1-2\",8\n\nVALUATIONS\n",
+	)
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(v.RawSections) != 1 {
+		t.Fatalf("%d raw sections, want 1", len(v.RawSections))
+	}
+
+	raw := v.RawSections[0]
+	if raw.Header != "CHARGING SESSIONS" || !slices.Equal(raw.Columns, []string{"Date", "Energy (kWh)"}) {
+		t.Errorf("raw section = %s %v, want CHARGING SESSIONS [Date Energy (kWh)]", raw.Header, raw.Columns)
+	}
+
+	if len(raw.Rows) != 2 || raw.Rows[0]["Energy (kWh)"] != "12.5" || raw.Rows[1]["Date"] != "2024-1-2" {
+		t.Errorf("raw rows = %v", raw.Rows)
+	}
+
+	if len(v.Tires) != 1 || len(v.Valuations) != 1 {
+		t.Errorf("%d tires and %d valuations, want 1 and 1", len(v.Tires), len(v.Valuations))
+	}
+
+	var streamed int
+
+	for _, err := range roadtrip.StreamTires(bytes.NewReader(buf), roadtrip.VehicleOptions{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		streamed++
+	}
+
+	if streamed != len(v.Tires) {
+		t.Errorf("streamed %d tires, want %d", streamed, len(v.Tires))
+	}
+
+	// A malformed row of an unknown section is reported at its own line.
+	malformed := replaceOnce(t, buf, "\"2024-1-2\",8", `2"024-1-2,8`)
+	line := bytes.Count(buf[:bytes.Index(buf, []byte("CHARGING SESSIONS"))], []byte("\n")) + 4
+
+	_, err = roadtrip.NewVehicleFromFS(fixtureFS(malformed), fixtureName,
+		roadtrip.VehicleOptions{})
+
+	var parseErr *roadtrip.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Section != "CHARGING SESSIONS" || parseErr.Line != line {
+		t.Errorf("error = %v, want CHARGING SESSIONS line %d", err, line)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := v.FuelRecords[1].Odometer; got != (roadtrip.Distance{Value: 621, Unit: roadtrip.Miles}) {
+		t.Errorf("Odometer = %v, want 621 mi", got)
+	}
+
+	if got := v.Valuations[0].Odometer; got.Unit != roadtrip.Miles {
+		t.Errorf("valuation Odometer = %v, want unit from VEHICLE", got)
+	}
+
+	buf := readFixture(t, europeanFile)
+
+	buf = replaceAll(t, buf, "(mi", "(km")
+	buf = replaceOnce(t, buf, `"Golf";"mi"`, `"Golf";"km"`)
+
+	km, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fuel := km.FuelRecords[1]
+	if fuel.Odometer != (roadtrip.Distance{Value: 1612, Unit: roadtrip.Kilometers}) ||
+		fuel.TripDistance.Unit != roadtrip.Kilometers {
+		t.Errorf("fuel Odometer, TripDistance = %v, %v, want 1612 km and km", fuel.Odometer, fuel.TripDistance)
+	}
+
+	if got := km.Trips[0].EndOdometer.Sub(km.Trips[0].StartOdometer); got.String() != "612 km" {
+		t.Errorf("trip distance = %v, want 612 km", got)
+	}
+
+	if got := km.Valuations[0].Odometer.Unit; got != roadtrip.Kilometers {
+		t.Errorf("valuation unit = %s, want km", got)
+	}
+
+	for r, err := range roadtrip.StreamMaintenanceRecords(bytes.NewReader(buf), roadtrip.VehicleOptions{}) {
+		if err != nil || r.Odometer != (roadtrip.Distance{Value: 1200, Unit: roadtrip.Kilometers}) {
+			t.Errorf("streamed Odometer = %v, %v, want 1200 km", r.Odometer, err)
+		}
+	}
+
+	if got := (roadtrip.Distance{Value: 10, Unit: roadtrip.Miles}).Kilometers(); got != 16.09344 {
+		t.Errorf("10 mi = %v km, want 16.09344", got)
+	}
+
+	mixed := roadtrip.Distance{Value: 10, Unit: roadtrip.Miles}.
+		Add(roadtrip.Distance{Value: 1.609344, Unit: roadtrip.Kilometers})
+	if mixed.String() != "11 mi" {
+		t.Errorf("10 mi + 1.609344 km = %v, want 11 mi", mixed)
+	}
+
+	var padded roadtrip.Distance
+	if err := padded.UnmarshalCSV([]byte(" 621 ")); err != nil || padded.Value != 621 {
+		t.Errorf("UnmarshalCSV(\" 621 \") = %v, %v, want 621", padded, err)
+	}
+}
+
+func TestVolume(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := v.FuelRecords[0].FillAmount; got != (roadtrip.Volume{Value: 17.743, Unit: roadtrip.USGallons}) {
+		t.Errorf("FillAmount = %v, want 17.743 gal", got)
+	}
+
+	if got := v.Vehicles[0].Tank1Units; got != roadtrip.USGallons {
+		t.Errorf("Tank1Units = %q, want gal", got)
+	}
+
+	liters, err := roadtrip.Volume{Value: 10, Unit: roadtrip.USGallons}.In(roadtrip.Liters)
+	if err != nil || liters.String() != "37.85411784 L" {
+		t.Errorf("10 gal = %v, %v, want 37.85411784 L", liters, err)
+	}
+
+	imperial, err := roadtrip.Volume{Value: 4.54609, Unit: roadtrip.Liters}.In(roadtrip.ImperialGallons)
+	if err != nil || imperial.Value != 1 {
+		t.Errorf("4.54609 L = %v, %v, want 1 imp gal", imperial, err)
+	}
+
+	_, err = roadtrip.Volume{Value: 10, Unit: roadtrip.KilowattHours}.In(roadtrip.Liters)
+	if !errors.Is(err, roadtrip.ErrIncompatibleUnits) {
+		t.Errorf("kWh to L error = %v, want ErrIncompatibleUnits", err)
+	}
+
+	for _, tt := range []struct {
+		in   string
+		want roadtrip.VolumeUnit
+	}{
+		{in: "Gal", want: roadtrip.USGallons},
+		{in: "L", want: roadtrip.Liters},
+		{in: "Imp Gal", want: roadtrip.ImperialGallons},
+		{in: "kWh", want: roadtrip.KilowattHours},
+	} {
+		if got, err := roadtrip.ParseVolumeUnit(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseVolumeUnit(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestFuelEconomy(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := v.FuelRecords[1].MPG; got != (roadtrip.FuelEconomy{Value: 17.9214, Unit: roadtrip.MilesPerGallon}) {
+		t.Errorf("MPG = %v, want 17.9214 MPG", got)
+	}
+
+	avg, err := v.AverageFuelEconomy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if avg.Unit != roadtrip.MilesPerGallon || avg.Value < 20 || avg.Value > 21 {
+		t.Errorf("AverageFuelEconomy = %v, want about 20.6 MPG", avg)
+	}
+
+	metric, err := roadtrip.FuelEconomy{Value: 30, Unit: roadtrip.MilesPerGallon}.In(roadtrip.LitersPer100Kilometers)
+	if err != nil || math.Abs(metric.Value-7.8405) > 0.0001 {
+		t.Errorf("30 MPG = %v, %v, want 7.8405 L/100km", metric, err)
+	}
+
+	back, err := metric.In(roadtrip.KilometersPerLiter)
+	if err != nil || math.Abs(back.Value-12.7543) > 0.0001 {
+		t.Errorf("%v = %v, %v, want 12.7543 km/L", metric, back, err)
+	}
+
+	ev, err := roadtrip.FuelEconomy{Value: 4, Unit: roadtrip.MilesPerKilowattHour}.
+		In(roadtrip.KilowattHoursPer100Kilometers)
+	if err != nil || math.Abs(ev.Value-15.5343) > 0.0001 {
+		t.Errorf("4 mi/kWh = %v, %v, want 15.5343 kWh/100km", ev, err)
+	}
+
+	_, err = metric.In(roadtrip.MilesPerKilowattHour)
+	if !errors.Is(err, roadtrip.ErrIncompatibleUnits) {
+		t.Errorf("L/100km to mi/kWh error = %v, want ErrIncompatibleUnits", err)
+	}
+
+	if _, err := (roadtrip.Vehicle{}).AverageFuelEconomy(); !errors.Is(err, roadtrip.ErrNotEnoughFuelRecords) {
+		t.Errorf("empty AverageFuelEconomy error = %v, want ErrNotEnoughFuelRecords", err)
+	}
+
+	// Partial fill-ups are carried into the next full fill-up, and a reset
+	// leaves out the distance since the fill-up before it.
+	fill := func(odometer, gallons float64, partial, reset bool) roadtrip.FuelRecord {
+		return roadtrip.FuelRecord{
+			Odometer:    roadtrip.Distance{Value: odometer, Unit: roadtrip.Miles},
+			FillAmount:  roadtrip.Volume{Value: gallons, Unit: roadtrip.USGallons},
+			PartialFill: roadtrip.Bool(partial),
+			Reset:       roadtrip.Bool(reset),
+		}
+	}
+
+	log := roadtrip.Vehicle{FuelRecords: []roadtrip.FuelRecord{
+		fill(1000, 12, false, true),
+		fill(1300, 10, false, false),
+		fill(1400, 3, true, false),
+		fill(1600, 7, false, false),
+		fill(2500, 12, false, true),
+		fill(2800, 10, false, false),
+		fill(2900, 4, true, false),
+	}}
+
+	avg, err = log.AverageFuelEconomy()
+	if err != nil || math.Abs(avg.Value-30) > 0.0001 {
+		t.Errorf("AverageFuelEconomy with partial and reset fill-ups = %v, %v, want 30 MPG", avg, err)
+	}
+}
+
+func TestMoney(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domestic, err := v.TotalFuelCost()
+	if err != nil || domestic.Currency != "USD" {
+		t.Fatalf("TotalFuelCost = %v, %v, want USD", domestic, err)
+	}
+
+	buf := readFixture(t, exampleFile,
+		",0,,1,29.734717", ",0,CAD,0.7423,29.734717",
+	)
+
+	v, err = roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fuel := v.FuelRecords[1]
+	if fuel.TotalPrice != (roadtrip.Money{Amount: 71.3, Currency: "CAD"}) || fuel.CurrencyRate != 0.7423 {
+		t.Errorf("TotalPrice, CurrencyRate = %v, %v, want 71.3 CAD at 0.7423", fuel.TotalPrice, fuel.CurrencyRate)
+	}
+
+	home, err := fuel.HomeTotalPrice(v.HomeCurrency())
+	if err != nil || home.Currency != "USD" || math.Abs(home.Amount-52.92599) > 0.00001 {
+		t.Errorf("HomeTotalPrice = %v, %v, want 52.92599 USD", home, err)
+	}
+
+	total, err := v.TotalFuelCost()
+	if err != nil || math.Abs(domestic.Amount-total.Amount-(71.3-52.92599)) > 0.00001 {
+		t.Errorf("TotalFuelCost = %v, %v, want %v less the exchange difference", total, err, domestic)
+	}
+
+	valuation := v.Valuations[0]
+	if got, err := valuation.HomePrice(v.HomeCurrency()); err != nil || got != valuation.Price {
+		t.Errorf("HomePrice = %v, %v, want %v", got, err, valuation.Price)
+	}
+
+	valuation.Price.Currency = "CAD"
+	if _, err := valuation.HomePrice(v.HomeCurrency()); !errors.Is(err, roadtrip.ErrMissingCurrencyRate) {
+		t.Errorf("HomePrice of a CAD valuation error = %v, want ErrMissingCurrencyRate", err)
+	}
+
+	_, err = roadtrip.Money{Amount: 1, Currency: "USD"}.Add(roadtrip.Money{Amount: 1, Currency: "EUR"})
+	if !errors.Is(err, roadtrip.ErrCurrencyMismatch) {
+		t.Errorf("USD + EUR error = %v, want ErrCurrencyMismatch", err)
+	}
+
+	if got, err := roadtrip.ParseCurrency("978"); err != nil || got != "EUR" {
+		t.Errorf("ParseCurrency(978) = %v, %v, want EUR", got, err)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := v.FuelRecords[0]
+	if first.Flags != 9 || !slices.Equal(first.Flags.Bits(), []int{0, 3}) || !first.IsReset() {
+		t.Errorf("first fill-up Flags = %v, want 9 with bits [0 3] and a reset", first.Flags)
+	}
+
+	if v.FuelRecords[1].IsReset() || v.FuelRecords[1].Flags != 0 {
+		t.Errorf("second fill-up Flags = %v, want no flags", v.FuelRecords[1].Flags)
+	}
+
+	// Only the Reset column marks a reset, whatever the flags.
+	if !(roadtrip.FuelRecord{Reset: true}).IsReset() || (roadtrip.FuelRecord{Flags: 1}).IsReset() {
+		t.Error("IsReset() does not follow the Reset column alone")
+	}
+
+	if got := v.Vehicles[0].Flags; got.String() != "24577" || !slices.Equal(got.Bits(), []int{0, 13, 14}) {
+		t.Errorf("vehicle Flags = %v bits %v, want 24577 bits [0 13 14]", got, got.Bits())
+	}
+
+	if !v.MaintenanceRecords[2].Flags.Has(1 << 8) {
+		t.Errorf("maintenance Flags = %v, want bit 8", v.MaintenanceRecords[2].Flags)
+	}
+}
+
+func TestBool(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var partial, reset int
+
+	for _, fuel := range v.FuelRecords {
+		if fuel.PartialFill {
+			partial++
+		}
+
+		if fuel.Reset {
+			reset++
+		}
+	}
+
+	if partial != 2 || reset != 1 || !v.FuelRecords[0].Reset {
+		t.Errorf("%d partial and %d reset fill-ups, want 2 and 1 on the first", partial, reset)
+	}
+
+	if v.Vehicles[0].TripCompTimeEnabled {
+		t.Error("TripCompTimeEnabled = true, want false for an empty cell")
+	}
+
+	for in, want := range map[string]bool{"": false, "0": false, "No": false, "1": true, "Partiel": true} {
+		var b roadtrip.Bool
+		if err := b.UnmarshalCSV([]byte(in)); err != nil || bool(b) != want {
+			t.Errorf("UnmarshalCSV(%q) = %v, %v, want %v", in, b, err, want)
+		}
+	}
+}
+
+func TestCategories(t *testing.T) {
+	buf := readFixture(t, exampleFile,
+		`"Montrose Shell",,,,,0`, `"Montrose Shell",,,,"Business, Acme Corp",0`,
+		`"H‑E‑B Bulverde",,,,,0`, `"H‑E‑B Bulverde",,,,"BUSINESS",0`,
+	)
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fuel := v.FuelRecords[1]
+	if !slices.Equal(fuel.Categories, roadtrip.Categories{"Business", "Acme Corp"}) ||
+		!fuel.Categories.HasCategory("business") {
+		t.Errorf("Categories = %q, want [Business Acme Corp]", fuel.Categories)
+	}
+
+	if v.FuelRecords[0].Categories != nil {
+		t.Errorf("empty Categories = %q, want nil", v.FuelRecords[0].Categories)
+	}
+
+	if got := v.CategoryList(); !slices.Equal(got, []string{"Acme Corp", "Business", "Vacation"}) {
+		t.Errorf("CategoryList = %q, want [Acme Corp Business Vacation]", got)
+	}
+
+	index := v.CategoryIndex()
+	if got := index["business"].FuelRecords; !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("business fuel records = %v, want [1 2]", got)
+	}
+
+	if got := index["vacation"].Trips; !slices.Equal(got, []int{3}) {
+		t.Errorf("Vacation trips = %v, want [3]", got)
+	}
+
+	// Every listed name can be looked up as written.
+	for _, name := range v.CategoryList() {
+		if _, ok := v.Category(name); !ok {
+			t.Errorf("Category(%q) not found", name)
+		}
+	}
+
+	if usage, ok := v.Category("BUSINESS"); !ok || !slices.Equal(usage.FuelRecords, []int{1, 2}) {
+		t.Errorf("Category(BUSINESS) = %v, %v, want fuel records [1 2]", usage, ok)
+	}
+
+	if _, ok := v.Category("Personal"); ok {
+		t.Error("Category(Personal) found, want no records")
+	}
+}
+
+func TestAppStyleTimestamp(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{Location: denver})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fill := v.FuelRecords[0].Date
+	if !fill.HasTime() {
+		t.Errorf("%q HasTime = false, want true", fill)
+	}
+
+	want := time.Date(2023, 2, 3, 18, 39, 0, 0, denver)
+	if got := fill.MustParse(); !got.Equal(want) {
+		t.Errorf("%q MustParse = %v, want %v", fill, got, want)
+	}
+
+	purchase := v.Valuations[0].Date
+	if purchase.HasTime() {
+		t.Errorf("%q HasTime = true, want false", purchase)
+	}
+
+	want = time.Date(2023, 1, 30, 0, 0, 0, 0, denver)
+	if got := purchase.Time(); !got.Equal(want) {
+		t.Errorf("%q Time = %v, want %v", purchase, got, want)
+	}
+
+	var ts roadtrip.AppStyleTimestamp
+
+	err = ts.UnmarshalText([]byte("2023-3-20"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text, _ := ts.MarshalText(); string(text) != "2023-3-20" || ts.Location() != time.UTC {
+		t.Errorf("MarshalText = %q in %v, want 2023-3-20 in UTC", text, ts.Location())
+	}
+
+	_, err = roadtrip.NewAppStyleTimestamp("March 20", nil).Parse()
+	if !errors.Is(err, roadtrip.ErrInvalidTimestamp) {
+		t.Errorf("Parse error = %v, want %v", err, roadtrip.ErrInvalidTimestamp)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParse did not panic")
+		}
+	}()
+
+	roadtrip.NewAppStyleTimestamp("March 20", nil).MustParse()
+}
+
+func TestTripComputer(t *testing.T) {
+	buf := readFixture(t, europeanFile,
+		";2;;;;;0", `;2;38,5;62,5;18,5;"5:42";0`,
+	)
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fuel := v.FuelRecords[1]
+
+	if want := (roadtrip.FuelEconomy{Value: 38.5, Unit: roadtrip.MilesPerGallon}); fuel.FuelEconomy != want {
+		t.Errorf("FuelEconomy = %v, want %v", fuel.FuelEconomy, want)
+	}
+
+	if got := fuel.AvgSpeed.In(roadtrip.KilometersPerHour); math.Abs(got.Value-100.584) > 0.001 {
+		t.Errorf("AvgSpeed = %v, want 100.584 km/h", got)
+	}
+
+	if got := fuel.Temperature.In(roadtrip.Fahrenheit); math.Abs(got.Value-65.3) > 0.001 || got.Unit != "F" {
+		t.Errorf("Temperature = %v, want 65.3 F", got)
+	}
+
+	// Units this package does not know are never converted.
+	knots := roadtrip.Speed{Value: 10, Unit: "knots"}
+	if got := knots.In(roadtrip.KilometersPerHour); got != knots {
+		t.Errorf("10 knots In(km/h) = %v, want 10 knots unchanged", got)
+	}
+
+	if got := fuel.AvgSpeed.In("knots"); got != fuel.AvgSpeed {
+		t.Errorf("AvgSpeed In(knots) = %v, want %v unchanged", got, fuel.AvgSpeed)
+	}
+
+	kelvin := roadtrip.Temperature{Value: 300, Unit: "K"}
+	if got := kelvin.In(roadtrip.Fahrenheit); got != kelvin {
+		t.Errorf("300 K In(F) = %v, want 300 K unchanged", got)
+	}
+
+	if want := 5*time.Hour + 42*time.Minute; fuel.DriveTime.Duration != want {
+		t.Errorf("DriveTime = %v, want %v", fuel.DriveTime, want)
+	}
+
+	var d roadtrip.Duration
+
+	for text, want := range map[string]time.Duration{
+		"0:45:30": 45*time.Minute + 30*time.Second,
+		"1h25m":   85 * time.Minute,
+		"":        0,
+	} {
+		if err := d.UnmarshalCSV([]byte(text)); err != nil || d.Duration != want {
+			t.Errorf("Duration %q = %v, %v, want %v", text, d, err, want)
+		}
+	}
+
+	if err := d.UnmarshalCSV([]byte("1:xx")); err != nil || d.Duration != 0 || d.Raw != "1:xx" {
+		t.Errorf("Duration %q = %+v, %v, want zero with Raw kept", "1:xx", d, err)
+	}
+
+	if _, err := roadtrip.ParseDuration("1:xx"); !errors.Is(err, roadtrip.ErrInvalidDuration) {
+		t.Errorf("ParseDuration error = %v, want %v", err, roadtrip.ErrInvalidDuration)
+	}
+}
+
+func TestTires(t *testing.T) {
+	buf := readFixture(t, exampleFile,
+		`"Default Tires",,,,,`, `"Default Tires",,,"P225/45R17 91W",2.5,`,
+	)
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tire := v.Tires[0]
+
+	want := roadtrip.TireSize{
+		Raw:          "P225/45R17 91W",
+		Service:      "P",
+		Width:        225,
+		AspectRatio:  45,
+		Construction: "R",
+		RimDiameter:  17,
+	}
+	if tire.Size != want {
+		t.Errorf("Size = %+v, want %+v", tire.Size, want)
+	}
+
+	if tire.Age.Years != 1 || tire.Age.Months != 10 || tire.Age.TotalMonths() != 22 ||
+		tire.Age.String() != "1 year 10 months" {
+		t.Errorf("Age = %+v, want 1 year 10 months", tire.Age)
+	}
+
+	if got := tire.CorrectedDistance(); math.Abs(got.Value-31549.5) > 0.001 || got.Unit != roadtrip.Miles {
+		t.Errorf("CorrectedDistance = %v, want 31549.5 mi", got)
+	}
+
+	plus, err := roadtrip.ParseTireSize("245/40ZR18")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := plus.DiameterDifference(tire.Size)
+	if err != nil || math.Abs(diff-2.98) > 0.01 {
+		t.Errorf("DiameterDifference = %.2f, %v, want 2.98", diff, err)
+	}
+
+	if _, err := roadtrip.ParseTireSize("33x12.50R15"); !errors.Is(err, roadtrip.ErrInvalidTireSize) {
+		t.Errorf("ParseTireSize error = %v, want %v", err, roadtrip.ErrInvalidTireSize)
+	}
+
+	if _, err := roadtrip.ParseTireSize("1 fortnight"); !errors.Is(err, roadtrip.ErrInvalidTireSize) {
+		t.Errorf("ParseTireSize error = %v, want %v", err, roadtrip.ErrInvalidTireSize)
+	}
+
+	if _, err := roadtrip.ParseTireAge("1 fortnight"); !errors.Is(err, roadtrip.ErrInvalidTireAge) {
+		t.Errorf("ParseTireAge error = %v, want %v", err, roadtrip.ErrInvalidTireAge)
+	}
+
+	var age roadtrip.TireAge
+	if err := age.UnmarshalCSV([]byte("1 fortnight")); err != nil || age.TotalMonths() != 0 || age.Raw != "1 fortnight" {
+		t.Errorf("TireAge %q = %+v, %v, want zero with Raw kept", "1 fortnight", age, err)
+	}
+}
+
+func TestVehicleAttributes(t *testing.T) {
+	buf := readFixture(t, exampleFile,
+		"License Plate:\nVIN:\n", "License Plate: 8ABC123\nVIN: 1hgcm82633a004352\n",
+		"Notes:\n\"", "Notes:\nGaraged at 5:30 pm\nCover in trunk\n\"",
+	)
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vehicle := v.Vehicles[0]
+
+	if plate, ok := vehicle.Attribute("license plate"); !ok || plate != "8ABC123" {
+		t.Errorf("License Plate = %q, %v, want 8ABC123", plate, ok)
+	}
+
+	if got := vehicle.Attributes["Notes"]; got != "Garaged at 5:30 pm\nCover in trunk" {
+		t.Errorf("Notes attribute = %q", got)
+	}
+
+	if _, ok := vehicle.Attributes["Insurance Name"]; ok || len(vehicle.Attributes) != 3 {
+		t.Errorf("Attributes = %q, want only non-empty values", vehicle.Attributes)
+	}
+
+	vin := vehicle.VIN()
+	if err := vin.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if vin.WMI() != "1HG" || vin.Region() != "North America" {
+		t.Errorf("VIN %s WMI, Region = %s, %s, want 1HG, North America", vin, vin.WMI(), vin.Region())
+	}
+
+	if year, err := vin.ModelYear(); err != nil || year != 2003 {
+		t.Errorf("ModelYear = %d, %v, want 2003", year, err)
+	}
+
+	if year, _ := roadtrip.ParseVIN("5YJ3E1EA7KF317000").ModelYear(); year != 2019 {
+		t.Errorf("ModelYear = %d, want 2019", year)
+	}
+
+	if err := roadtrip.ParseVIN("1HGCM82643A004352").Validate(); !errors.Is(err, roadtrip.ErrVINCheckDigit) {
+		t.Errorf("Validate error = %v, want %v", err, roadtrip.ErrVINCheckDigit)
+	}
+
+	if err := roadtrip.ParseVIN("1HGCM8263OA004352").Validate(); !errors.Is(err, roadtrip.ErrInvalidVIN) {
+		t.Errorf("Validate error = %v, want %v", err, roadtrip.ErrInvalidVIN)
+	}
+}
+
+func TestColumnFixups(t *testing.T) {
+	buf := readFixture(t, exampleFile)
+
+	v := roadtrip.NewVehicle(roadtrip.VehicleOptions{})
+
+	err := v.UnmarshalRoadtrip(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vehicle := v.Vehicles[0]
+	if vehicle.Tank1Units != roadtrip.USGallons || vehicle.HomeCurrency != "USD" || vehicle.Tank2Units != "" {
+		t.Errorf("Tank1Units, HomeCurrency, Tank2Units = %q, %q, %q, want gal, USD and none",
+			vehicle.Tank1Units, vehicle.HomeCurrency, vehicle.Tank2Units)
+	}
+
+	if !bytes.Equal(v.Raw, buf) || len(v.UnmappedColumns) != 0 {
+		t.Errorf("Raw modified or UnmappedColumns = %v, want the file as read", v.UnmappedColumns)
+	}
+
+	// A row which writes the tank columns needs no fix-up.
+	full := replaceOnce(t, buf, "MPG,MPH,C,,\n", "MPG,MPH,C,,,Gasoline,Electric,kWh\n")
+
+	err = v.UnmarshalRoadtrip(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := v.Vehicles[0]; got.Tank2Type != "Electric" || got.Tank2Units != roadtrip.KilowattHours {
+		t.Errorf("Tank2Type, Tank2Units = %q, %q, want Electric, kWh", got.Tank2Type, got.Tank2Units)
+	}
+
+	// Mismatches which no fix-up reconciles are reported.
+	short := replaceOnce(t, buf, "MPG,MPH,C,,\n", "MPG,MPH,C\n")
+
+	err = v.UnmarshalRoadtrip(short)
+	if !errors.Is(err, csv.ErrFieldCount) {
+		t.Errorf("error = %v, want %v", err, csv.ErrFieldCount)
+	}
+}
+
+func TestVehicleInfo(t *testing.T) {
+	v, err := roadtrip.NewVehicleFromFile(exampleFile, roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := v.Info()
+	if err != nil || info.Name != "2022 Portofino M" {
+		t.Fatalf("Info = %q, %v, want 2022 Portofino M", info.Name, err)
+	}
+
+	if v.Name() != info.Name || v.DistanceUnit() != roadtrip.Miles || v.TankCapacity().Unit != roadtrip.USGallons {
+		t.Errorf("Name, DistanceUnit, TankCapacity = %q, %q, %v", v.Name(), v.DistanceUnit(), v.TankCapacity())
+	}
+
+	tests := []struct {
+		name     string
+		vehicles []roadtrip.VehicleRecord
+		wantErr  error
+	}{
+		{name: "missing", wantErr: roadtrip.ErrNoVehicle},
+		{name: "duplicated", vehicles: []roadtrip.VehicleRecord{info, info}, wantErr: roadtrip.ErrMultipleVehicles},
+	}
+
+	for _, tt := range tests {
+		v.Vehicles = tt.vehicles
+
+		_, err := v.Info()
+
+		var countErr *roadtrip.VehicleCountError
+		if !errors.Is(err, tt.wantErr) || !errors.As(err, &countErr) || countErr.Count != len(tt.vehicles) {
+			t.Errorf("%s: Info error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+
+		if v.Name() != "" || v.HomeCurrency() != "" {
+			t.Errorf("%s: Name, HomeCurrency = %q, %q, want empty", tt.name, v.Name(), v.HomeCurrency())
+		}
+
+		value := v.LogValue()
+		if value.Kind() != slog.KindGroup || !strings.Contains(value.String(), "vehicleError") {
+			t.Errorf("%s: LogValue = %v, want a group with vehicleError", tt.name, value)
+		}
+	}
+}
+
+func TestTanks(t *testing.T) {
+	// Turn the vehicle into a plug-in hybrid with two charges, written out
+	// of odometer order.
+	buf := readFixture(t, europeanFile,
+		"MPG;MPH;C;;\n", "MPG;MPH;C;;;Gasoline;Electric;kWh\n",
+		"9,993682;2;;;;;0\n", "9,993682;2;;;;;0\n"+
+			"1800;100;\"2024-5-11 7:00\";25;;0,35;8,75;;;;;\"Home\";;;;;0;;1;;;4;;;;;2\n"+
+			"1700;88;\"2024-5-10 7:00\";20;;0,35;7;;;;;\"Home\";;;;;0;;1;;;3;;;;;2\n",
+	)
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := v.FuelRecords[3]; got.Tank() != roadtrip.SecondaryTank || got.FillAmount.Unit != roadtrip.KilowattHours {
+		t.Errorf("charge Tank, FillAmount = %d, %v, want 2 and kWh", got.Tank(), got.FillAmount)
+	}
+
+	if got := v.TankFuelRecords(roadtrip.SecondaryTank); got[0].Odometer.Value != 1700 {
+		t.Errorf("TankFuelRecords starts at %v, want 1700 mi", got[0].Odometer)
+	}
+
+	summaries, err := v.TankSummaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(summaries) != 2 {
+		t.Fatalf("TankSummaries = %d tanks, want 2", len(summaries))
+	}
+
+	gasoline, electric := summaries[0], summaries[1]
+
+	if gasoline.Type != "Gasoline" || gasoline.FillUps != 2 || gasoline.Volume.String() != "86.75 L" ||
+		math.Abs(gasoline.Cost.Amount-158.12) > 0.001 {
+		t.Errorf("gasoline = %+v, want 2 fill-ups of 86.75 L costing 158.12 EUR", gasoline)
+	}
+
+	if electric.Type != "Electric" || electric.FillUps != 2 || electric.Volume.String() != "45 kWh" ||
+		electric.Cost.String() != "15.75 EUR" {
+		t.Errorf("electric = %+v, want 2 charges of 45 kWh costing 15.75 EUR", electric)
+	}
+
+	want := roadtrip.FuelEconomy{Value: 4, Unit: roadtrip.MilesPerKilowattHour}
+	if electric.Economy != want || electric.EconomyErr != nil {
+		t.Errorf("electric Economy = %v, %v, want 4 mi/kWh", electric.Economy, electric.EconomyErr)
+	}
+
+	economy, err := v.AverageFuelEconomy()
+	if err != nil || economy.Unit != roadtrip.MilesPerGallon || math.Abs(economy.Value-56.16) > 0.01 {
+		t.Errorf("AverageFuelEconomy = %v, %v, want 56.16 MPG from the gasoline tank", economy, err)
+	}
+
+	// Once the gasoline tank is filled after the charges, part of the
+	// distance between its fill-ups was driven on electricity.
+	v.FuelRecords = append(v.FuelRecords, roadtrip.FuelRecord{
+		Odometer:   roadtrip.Distance{Value: 2000, Unit: roadtrip.Miles},
+		FillAmount: roadtrip.Volume{Value: 30, Unit: roadtrip.Liters},
+	})
+
+	if _, err := v.TankFuelEconomy(roadtrip.PrimaryTank); !errors.Is(err, roadtrip.ErrInterleavedTanks) {
+		t.Errorf("interleaved TankFuelEconomy error = %v, want %v", err, roadtrip.ErrInterleavedTanks)
+	}
+
+	summaries, err = v.TankSummaries()
+	if err != nil || summaries[0].Economy.Value != 0 ||
+		!errors.Is(summaries[0].EconomyErr, roadtrip.ErrInterleavedTanks) || summaries[1].Economy.Value != 4 {
+		t.Errorf("interleaved TankSummaries = %+v, %v, want no gasoline economy and 4 mi/kWh", summaries, err)
+	}
+}
+
+func TestCharging(t *testing.T) {
+	buf := readFixture(t, europeanFile)
+
+	// Turn the vehicle into an EV with a partial charge between two full
+	// charges and another after the last one.
+	buf = replaceAll(t, buf, ";L;", ";kilowatt hours;")
+	buf = replaceOnce(t, buf, "9,993682;2;;;;;0\n", "9,993682;2;;;;;0\n"+
+		"1700;88;\"2024-5-10 7:00\";10;kWh;0,35;3,5;Partial;;;;\"Home\";;;;;0;;1;;;3;;;;;0\n"+
+		"1800;100;\"2024-5-11 7:00\";30;kWh;0,35;10,5;;;;;\"Home\";;;;;0;;1;;;4;;;;;0\n"+
+		"1850;50;\"2024-5-12 7:00\";5;kWh;0,35;1,75;Partial;;;;\"Home\";;;;;0;;1;;;5;;;;;0\n")
+
+	v, err := roadtrip.NewVehicleFromFS(fixtureFS(buf), fixtureName,
+		roadtrip.VehicleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := v.ChargingSessions()
+	if len(sessions) != 5 || !sessions[2].IsPartialCharge() || sessions[3].IsPartialCharge() {
+		t.Fatalf("ChargingSessions = %d, want 5 with the third partial", len(sessions))
+	}
+
+	if got := v.ChargingEnergy(); got.String() != "131.75 kWh" {
+		t.Errorf("ChargingEnergy = %v, want 131.75 kWh", got)
+	}
+
+	efficiency, err := v.ChargingEfficiency(roadtrip.MilesPerKilowattHour)
+	if err != nil || math.Abs(efficiency.Value-800/81.25) > 0.0001 {
+		t.Errorf("ChargingEfficiency = %v, %v, want 9.846 mi/kWh", efficiency, err)
+	}
+
+	efficiency, err = v.ChargingEfficiency(roadtrip.KilowattHoursPer100Kilometers)
+	if err != nil || math.Abs(efficiency.Value-6.311) > 0.001 {
+		t.Errorf("ChargingEfficiency = %v, %v, want 6.311 kWh/100km", efficiency, err)
+	}
+
+	if _, err := v.ChargingEfficiency(roadtrip.MilesPerGallon); !errors.Is(err, roadtrip.ErrIncompatibleUnits) {
+		t.Errorf("ChargingEfficiency(MPG) error = %v, want %v", err, roadtrip.ErrIncompatibleUnits)
+	}
+
+	perKWh, err := v.ChargingCostPerKilowattHour()
+	if err != nil || perKWh.Currency != "EUR" || math.Abs(perKWh.Amount-173.87/131.75) > 0.0001 {
+		t.Errorf("ChargingCostPerKilowattHour = %v, %v, want 1.3197 EUR", perKWh, err)
+	}
+
+	perMile, err := v.ChargingCostPerDistance(roadtrip.Miles)
+	if err != nil || math.Abs(perMile.Amount-90.27/800) > 0.0001 {
+		t.Errorf("ChargingCostPerDistance = %v, %v, want 0.1128 EUR", perMile, err)
+	}
+
+	if _, err := (roadtrip.Vehicle{}).ChargingCostPerKilowattHour(); !errors.Is(err, roadtrip.ErrNotEnoughFuelRecords) {
+		t.Errorf("ChargingCostPerKilowattHour error = %v, want %v", err, roadtrip.ErrNotEnoughFuelRecords)
+	}
+
+	// A plug-in hybrid which took on fuel between charges drove part of the
+	// distance on fuel.
+	v.FuelRecords = append(v.FuelRecords, roadtrip.FuelRecord{
+		Odometer:   roadtrip.Distance{Value: 1750, Unit: roadtrip.Miles},
+		FillAmount: roadtrip.Volume{Value: 30, Unit: roadtrip.Liters},
+	})
+
+	if _, err := v.ChargingEfficiency(roadtrip.MilesPerKilowattHour); !errors.Is(err, roadtrip.ErrInterleavedTanks) {
+		t.Errorf("interleaved ChargingEfficiency error = %v, want %v", err, roadtrip.ErrInterleavedTanks)
+	}
+
+	if _, err := v.ChargingCostPerDistance(roadtrip.Miles); !errors.Is(err, roadtrip.ErrInterleavedTanks) {
+		t.Errorf("interleaved ChargingCostPerDistance error = %v, want %v", err, roadtrip.ErrInterleavedTanks)
+	}
+}
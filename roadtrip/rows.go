@@ -0,0 +1,503 @@
+package roadtrip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	cvslib "github.com/tiendc/go-csvlib"
+)
+
+// A sectionDecoder reads the CSV rows of a single section of a Road Trip data
+// file and decodes them one at a time into records of the section's struct
+// type.
+//
+// Rows are read ahead in batches and each batch is decoded by a single CSV
+// library decoder, which reads all of its rows before decoding the first.
+type sectionDecoder struct {
+	reader     *csv.Reader
+	header     []string
+	written    []string
+	headerLine int
+	section    string
+	options    []cvslib.DecodeOption
+
+	// batch is the number of rows read ahead at a time, or zero to read the
+	// rest of the section at once.
+	batch int
+
+	// pending holds the rows which have been read ahead but not yet
+	// returned, and decoder decodes those of them which are well formed.
+	pending []bufferedRow
+	decoder *cvslib.Decoder
+	eof     bool
+
+	// unit is the distance unit named by the section's column headers.
+	unit DistanceUnit
+
+	// location is the time zone assigned to the timestamps of each row.
+	location *time.Location
+
+	// unmapped holds the indexes of the header columns which have no
+	// corresponding record field.
+	unmapped []int
+}
+
+// A bufferedRow is a data row read ahead of decoding, with the result of the
+// read and the line number of each field within the section.
+type bufferedRow struct {
+	record []string
+	lines  []int
+	err    error
+}
+
+// line returns the line number of the start of the row within the section.
+func (row bufferedRow) line() int {
+	if len(row.lines) == 0 {
+		return 0
+	}
+
+	return row.lines[0]
+}
+
+// A fileFormat describes how the sections of a data file are written.
+type fileFormat struct {
+	delimiters Delimiters
+	pack       LanguagePack
+	version    int
+}
+
+// newSectionDecoder returns a [sectionDecoder] reading the contents of the
+// section from r. The target is a pointer to a slice of the section's record
+// type. It returns [io.EOF] if the section has no column header row.
+//
+// When the first data row has a different number of fields than the header
+// row, the [ColumnFixup] entries for the file version are applied to the
+// header to reconcile them.
+func newSectionDecoder(r io.Reader, section Section, target any, format fileFormat) (*sectionDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = format.delimiters.Field()
+	reader.FieldsPerRecord = -1
+
+	d := &sectionDecoder{
+		reader:     reader,
+		headerLine: section.HeaderLine,
+		section:    section.Canonical,
+		options:    decodeOptions(target, format.delimiters),
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, &ParseError{Section: d.section, Line: d.headerLine + 1, Err: err}
+	}
+
+	d.written = header
+
+	// The first data row is read ahead to reconcile the header with the
+	// number of fields in the data.
+	if first, ok := d.read(); ok {
+		d.pending = append(d.pending, first)
+
+		if first.err == nil {
+			d.written = reconcileColumns(header, len(first.record), columnFixups(format.version, d.section), format.pack)
+		}
+	}
+
+	d.header = make([]string, len(d.written))
+
+	// The header is translated to the canonical column names of the struct
+	// tags, both before and after a distance unit is normalized, so that
+	// packs may name either form.
+	for i, column := range d.written {
+		name, unit := normalizeDistanceHeader(format.pack.CanonicalColumnName(column))
+
+		d.header[i] = format.pack.CanonicalColumnName(name)
+		if unit != "" {
+			d.unit = unit
+		}
+	}
+
+	d.unmapped = unmappedColumns(target, d.header)
+
+	return d, nil
+}
+
+// unmappedColumns returns the indexes of the columns in header which do not
+// map to a field of the record type of target.
+func unmappedColumns(target any, header []string) []int {
+	recordType := reflect.TypeOf(target).Elem().Elem()
+
+	details, err := cvslib.GetHeaderDetails(reflect.New(recordType).Interface(), cvslib.DefaultTagName)
+	if err != nil {
+		return nil
+	}
+
+	known := make(map[string]bool, len(details))
+	for _, column := range details {
+		known[column.Name] = true
+	}
+
+	var unmapped []int
+
+	for i, column := range header {
+		if !known[column] {
+			unmapped = append(unmapped, i)
+		}
+	}
+
+	return unmapped
+}
+
+// unmappedColumnNames returns the names of the header columns which have no
+// corresponding record field, as written in the file.
+func (d *sectionDecoder) unmappedColumnNames() []string {
+	names := make([]string, 0, len(d.unmapped))
+	for _, i := range d.unmapped {
+		names = append(names, d.written[i])
+	}
+
+	return names
+}
+
+// next reads the next row of the section and decodes it into target, which
+// must be a pointer to the section's record struct. It returns [io.EOF] at the
+// end of the section. Decoding failures are returned as one or more
+// [ParseError] values joined together, one for each cell which failed.
+func (d *sectionDecoder) next(target any) error {
+	if d.decoder == nil {
+		d.fill()
+	}
+
+	if len(d.pending) == 0 {
+		return io.EOF
+	}
+
+	row, decoder := d.pending[0], d.decoder
+	d.pending = d.pending[1:]
+
+	// The next call reads ahead another batch once this one is used up.
+	if len(d.pending) == 0 {
+		d.decoder = nil
+	}
+
+	if row.err != nil {
+		return &ParseError{Section: d.section, Line: d.headerLine + row.line(), Err: row.err}
+	}
+
+	if len(row.record) != len(d.header) {
+		return &ParseError{
+			Section: d.section,
+			Line:    d.headerLine + row.line(),
+			Err:     fmt.Errorf("%w: row has %d fields, header has %d", csv.ErrFieldCount, len(row.record), len(d.header)),
+		}
+	}
+
+	err := decoder.DecodeOne(target)
+
+	d.setExtra(target, row.record)
+	setDistanceUnits(target, d.unit)
+	setLocation(target, d.location)
+
+	if r, ok := target.(decodedRecord); ok {
+		r.decoded()
+	}
+
+	if err != nil {
+		return d.parseErrors(err, row)
+	}
+
+	return nil
+}
+
+// fill reads ahead the next batch of rows and prepares a decoder for those of
+// them which are well formed, in order.
+func (d *sectionDecoder) fill() {
+	for !d.eof && (d.batch == 0 || len(d.pending) < d.batch) {
+		row, ok := d.read()
+		if !ok {
+			break
+		}
+
+		d.pending = append(d.pending, row)
+	}
+
+	records := [][]string{d.header}
+
+	for _, row := range d.pending {
+		if row.err == nil && len(row.record) == len(d.header) {
+			records = append(records, row.record)
+		}
+	}
+
+	options := append([]cvslib.DecodeOption{
+		func(cfg *cvslib.DecodeConfig) {
+			cfg.StopOnError = false
+		},
+	}, d.options...)
+
+	d.decoder = cvslib.NewDecoder(&recordReader{records: records}, options...)
+}
+
+// read returns the next data row of the section, or false at the end of the
+// section. A row which could not be read ends the section unless it is a
+// malformed CSV row, which the reader skips past.
+func (d *sectionDecoder) read() (bufferedRow, bool) {
+	record, err := d.reader.Read()
+	if errors.Is(err, io.EOF) {
+		d.eof = true
+
+		return bufferedRow{}, false
+	}
+
+	var parseErr *csv.ParseError
+	if err != nil && !errors.As(err, &parseErr) {
+		d.eof = true
+	}
+
+	row := bufferedRow{record: record, err: err}
+
+	if err != nil {
+		row.lines = []int{recordLine(d.reader, err)}
+
+		return row, true
+	}
+
+	row.lines = make([]int, len(record))
+	for i := range record {
+		row.lines[i], _ = d.reader.FieldPos(i)
+	}
+
+	return row, true
+}
+
+// recordLine returns the line number of the row last read by reader, whose
+// read returned err. [csv.Reader.FieldPos] panics after a failed read, so
+// the line of a malformed row is taken from its [csv.ParseError] instead.
+func recordLine(reader *csv.Reader, err error) int {
+	if err == nil {
+		line, _ := reader.FieldPos(0)
+
+		return line
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.StartLine
+	}
+
+	return 0
+}
+
+// A decodedRecord is a record type which derives some of its fields from
+// other columns of the same row once the row has been decoded.
+type decodedRecord interface {
+	decoded()
+}
+
+// setExtra stores the values of the unmapped columns of record in the Extra
+// field of the record struct that target points to.
+func (d *sectionDecoder) setExtra(target any, record []string) {
+	if len(d.unmapped) == 0 {
+		return
+	}
+
+	field := reflect.ValueOf(target).Elem().FieldByName("Extra")
+	if !field.IsValid() || field.Type() != reflect.TypeFor[map[string]string]() {
+		return
+	}
+
+	extra := make(map[string]string, len(d.unmapped))
+	for _, i := range d.unmapped {
+		extra[d.written[i]] = record[i]
+	}
+
+	field.Set(reflect.ValueOf(extra))
+}
+
+// parseErrors converts an error returned by the CSV library for row into
+// [ParseError] values.
+func (d *sectionDecoder) parseErrors(err error, row bufferedRow) error {
+	rowLine := row.line()
+
+	var rowErr *cvslib.RowErrors
+	if !errors.As(err, &rowErr) {
+		return &ParseError{Section: d.section, Line: d.headerLine + rowLine, Err: err}
+	}
+
+	var errs []error
+
+	for _, e := range rowErr.Unwrap() {
+		parseErr := &ParseError{Section: d.section, Line: d.headerLine + rowLine, Err: e}
+
+		var cellErr *cvslib.CellError
+		if errors.As(e, &cellErr) {
+			parseErr.Column = cellErr.Header()
+
+			if column := cellErr.Column(); column >= 0 && column < len(row.lines) {
+				parseErr.Line = d.headerLine + row.lines[column]
+				parseErr.Column = d.written[column]
+			}
+
+			parseErr.Value = cellErr.Value()
+			parseErr.Err = cellErr.Unwrap()
+		}
+
+		errs = append(errs, parseErr)
+	}
+
+	return errors.Join(errs...)
+}
+
+// A decodeMode controls how [sectionDecoder.decodeAll] handles rows which
+// fail to decode.
+type decodeMode int
+
+const (
+	// stopOnError stops at the first row which fails.
+	stopOnError decodeMode = iota
+
+	// collectErrors skips every row which fails and returns all errors.
+	collectErrors
+
+	// lenient keeps rows whose only problems are individual cells, leaving
+	// those fields unset, and skips rows which cannot be decoded at all.
+	// Every error is returned.
+	lenient
+)
+
+// decodeAll decodes every remaining row of the section into the slice that
+// target points to, handling rows which fail according to mode.
+func (d *sectionDecoder) decodeAll(target any, mode decodeMode) error {
+	slice := reflect.ValueOf(target).Elem()
+	rows := reflect.MakeSlice(slice.Type(), 0, 0)
+
+	var errs []error
+
+	for {
+		row := reflect.New(slice.Type().Elem())
+
+		err := d.next(row.Interface())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			if mode == stopOnError {
+				return err
+			}
+
+			errs = append(errs, err)
+
+			if mode != lenient || !isCellError(err) {
+				continue
+			}
+		}
+
+		rows = reflect.Append(rows, row.Elem())
+	}
+
+	slice.Set(rows)
+
+	return errors.Join(errs...)
+}
+
+// isCellError reports whether every [ParseError] in err is confined to a
+// single cell, so that the rest of the row was decoded.
+func isCellError(err error) bool {
+	for _, parseErr := range parseErrorList(err) {
+		if parseErr.Column == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decodeOptions builds the CSV decoder options for a section target. Columns
+// without a record field are ignored by the decoder and kept in Extra by the
+// [sectionDecoder]. Numeric columns are preprocessed so that values written
+// with a locale-specific decimal separator parse correctly. Localized column
+// names are translated in the header row by the [sectionDecoder].
+func decodeOptions(target any, delimiters Delimiters) []cvslib.DecodeOption {
+	options := []cvslib.DecodeOption{
+		func(cfg *cvslib.DecodeConfig) {
+			cfg.AllowUnrecognizedColumns = true
+		},
+	}
+
+	if delimiters.Decimal() == '.' {
+		return options
+	}
+
+	recordType := reflect.TypeOf(target).Elem().Elem()
+
+	details, err := cvslib.GetHeaderDetails(reflect.New(recordType).Interface(), cvslib.DefaultTagName)
+	if err != nil {
+		return options
+	}
+
+	return append(options,
+		func(cfg *cvslib.DecodeConfig) {
+			for _, column := range details {
+				if !isNumericColumn(column.DataType) {
+					continue
+				}
+
+				cfg.ConfigureColumn(column.Name, func(columnCfg *cvslib.DecodeColumnConfig) {
+					columnCfg.PreprocessorFuncs = append(columnCfg.PreprocessorFuncs, delimiters.NormalizeDecimal)
+				})
+			}
+		},
+	)
+}
+
+// numericTypes are the record field types, other than floating point kinds,
+// which hold a numeric value.
+var numericTypes = map[reflect.Type]bool{
+	reflect.TypeFor[Distance]():    true,
+	reflect.TypeFor[FuelEconomy](): true,
+	reflect.TypeFor[Money]():       true,
+	reflect.TypeFor[Speed]():       true,
+	reflect.TypeFor[Temperature](): true,
+	reflect.TypeFor[Volume]():      true,
+}
+
+// isNumericColumn reports whether a record field holds a numeric value which
+// may be written using a locale-specific decimal separator.
+func isNumericColumn(t reflect.Type) bool {
+	if numericTypes[t] {
+		return true
+	}
+
+	switch t.Kind() { //nolint:exhaustive // only numeric kinds are of interest
+	case reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// A recordReader is a [cvslib.Reader] which serves CSV records which have
+// already been split into fields.
+type recordReader struct {
+	records [][]string
+}
+
+// Read implements [cvslib.Reader].
+func (r *recordReader) Read() ([]string, error) {
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+
+	record := r.records[0]
+	r.records = r.records[1:]
+
+	return record, nil
+}
@@ -1,48 +1,27 @@
 package roadtrip
 
 import (
-	"fmt"
 	"log/slog"
-	"reflect"
-	"time"
+	"strings"
 )
 
-// AppStyleTimestamp contains a date or timestamp formatted the way the app
-// writes them.
-//
-// The iOS app uses an awkward time formatting style that is inconsistent
-// and challenging to parse. We abstract it into an interface to make it
-// simpler to massage and work with the data as it arrives.
-type AppStyleTimestamp string
-
-func (ts *AppStyleTimestamp) Raw() string {
-	return reflect.ValueOf(*ts).String()
-}
+// Bool is a boolean column value. The app marks a true value by writing a
+// word such as the column name, for example "Partial" in the Partial Fill
+// column or "Reset" in the Reset column, and leaves the cell empty otherwise.
+type Bool bool
 
-// MustParse turns a Road Trip app styled timestamp string into a proper Go
-// [time.Time] value. If parsing fails it will return an error.
-func (ts *AppStyleTimestamp) MustParse() (time.Time, error) {
-	tsString := ts.Raw()
-
-	datetimeValue, datetimeError := time.Parse("2006-1-2 15:04", tsString)
-	if datetimeError == nil {
-		return datetimeValue, nil
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. An empty cell or an
+// explicit false value such as "0", "false" or "no" is false, and any other
+// text is true.
+func (b *Bool) UnmarshalCSV(data []byte) error {
+	switch strings.ToLower(strings.TrimSpace(string(data))) {
+	case "", "0", "false", "no", "off":
+		*b = false
+	default:
+		*b = true
 	}
 
-	dateValue, dateError := time.Parse("2006-1-2", tsString)
-	if dateError == nil {
-		return dateValue, nil
-	}
-
-	return time.Time{}, fmt.Errorf("unable to parse date '%s'", tsString)
-}
-
-// Parse turns a Road Trip app styled timestamp string into a proper Go
-// [time.Time] value. Unlike MustParse(), Parse will silently fail if it
-// is given malformed or unexpected data.
-func (ts *AppStyleTimestamp) Parse() time.Time {
-	t, _ := ts.MustParse()
-	return t
+	return nil
 }
 
 // A FuelRecord contains a single fuel CSV row from the underlying Road Trip
@@ -52,46 +31,67 @@ func (ts *AppStyleTimestamp) Parse() time.Time {
 // A file will contain zero or more Fuel records in the FUEL RECORDS section of
 // the file.
 type FuelRecord struct {
-	Odometer     float64           `csv:"Odometer (mi)"`
-	TripDistance float64           `csv:"Trip Distance,omitempty"`
+	Odometer     Distance          `csv:"Odometer (mi)"`
+	TripDistance Distance          `csv:"Trip Distance,omitempty"`
 	Date         AppStyleTimestamp `csv:"Date"`
-	FillAmount   float64           `csv:"Fill Amount,omitempty"`
-	FillUnits    string            `csv:"Fill Units"`
-	PricePerUnit float64           `csv:"Price per Unit,omitempty"`
-	TotalPrice   float64           `csv:"Total Price,omitempty"`
-	PartialFill  string            `csv:"Partial Fill,omitempty"`
-	MPG          float64           `csv:"MPG,omitempty"`
+	FillAmount   Volume            `csv:"Fill Amount,omitempty"`
+	FillUnits    VolumeUnit        `csv:"Fill Units"`
+	PricePerUnit Money             `csv:"Price per Unit,omitempty"`
+	TotalPrice   Money             `csv:"Total Price,omitempty"`
+	PartialFill  Bool              `csv:"Partial Fill,omitempty"`
+	MPG          FuelEconomy       `csv:"MPG,omitempty"`
 	Note         string            `csv:"Note"`
 	Octane       string            `csv:"Octane"`
 	Location     string            `csv:"Location"`
 	Payment      string            `csv:"Payment"`
 	Conditions   string            `csv:"Conditions"`
-	Reset        string            `csv:"Reset"`
-	Categories   string            `csv:"Categories"`
-	Flags        string            `csv:"Flags"`
-	CurrencyCode int               `csv:"Currency Code,omitempty"`
-	CurrencyRate int               `csv:"Currency Rate,omitempty"`
+	Reset        Bool              `csv:"Reset"`
+	Categories   Categories        `csv:"Categories"`
+	Flags        Flags             `csv:"Flags"`
+	CurrencyCode Currency          `csv:"Currency Code,omitempty"`
+	CurrencyRate float64           `csv:"Currency Rate,omitempty"`
 	Latitude     float64           `csv:"Latitude,omitempty"`
 	Longitude    float64           `csv:"Longitude,omitempty"`
 	ID           int               `csv:"ID,omitempty"`
-	FuelEconomy  string            `csv:"Trip Comp Fuel Economy"`
-	AvgSpeed     string            `csv:"Trip Comp Avg. Speed"`
-	Temperature  float64           `csv:"Trip Comp Temperature,omitempty"`
-	DriveTime    string            `csv:"Trip Comp Drive Time"`
+	FuelEconomy  FuelEconomy       `csv:"Trip Comp Fuel Economy,omitempty"`
+	AvgSpeed     Speed             `csv:"Trip Comp Avg. Speed,omitempty"`
+	Temperature  Temperature       `csv:"Trip Comp Temperature,omitempty"`
+	DriveTime    Duration          `csv:"Trip Comp Drive Time"`
 	TankNumber   int               `csv:"Tank Number,omitempty"`
+	Extra        map[string]string `csv:"-"`
 }
 
 // LogValue is the handler for [log.slog] to emit structured output for a
 // [FuelRecord] object when logging.
 func (v FuelRecord) LogValue() slog.Value {
 	return slog.GroupValue(
-		slog.Float64("odometer", v.Odometer),
+		slog.String("odometer", v.Odometer.String()),
 		slog.String("date", v.Date.Raw()),
 		slog.String("location", v.Location),
-		slog.Float64("totalPrice", v.TotalPrice),
+		slog.String("totalPrice", v.TotalPrice.String()),
 	)
 }
 
+// decoded assigns the Fill Units to the FillAmount and the Currency Code to
+// the prices.
+func (v *FuelRecord) decoded() {
+	v.FillAmount.Unit = v.FillUnits
+	v.PricePerUnit.Currency = v.CurrencyCode
+	v.TotalPrice.Currency = v.CurrencyCode
+}
+
+// HomePricePerUnit returns the PricePerUnit converted to the home currency
+// using the record's Currency Rate.
+func (v FuelRecord) HomePricePerUnit(home Currency) (Money, error) {
+	return v.PricePerUnit.Exchange(v.CurrencyRate, home)
+}
+
+// HomeTotalPrice returns the TotalPrice converted to the home currency using
+// the record's Currency Rate.
+func (v FuelRecord) HomeTotalPrice(home Currency) (Money, error) {
+	return v.TotalPrice.Exchange(v.CurrencyRate, home)
+}
+
 // A MaintenceRecord is a single CSV row from the Road Trip data file and
 // represents a distinct vehicle maintenance activity with all of its
 // associated attributes.
@@ -101,38 +101,50 @@ func (v FuelRecord) LogValue() slog.Value {
 type MaintenanceRecord struct {
 	Description          string            `csv:"Description"`
 	Date                 AppStyleTimestamp `csv:"Date"`
-	Odometer             float64           `csv:"Odometer (mi.),omitempty"`
-	Cost                 float64           `csv:"Cost,omitempty"`
+	Odometer             Distance          `csv:"Odometer (mi.),omitempty"`
+	Cost                 Money             `csv:"Cost,omitempty"`
 	Note                 string            `csv:"Note"`
 	Location             string            `csv:"Location"`
 	Type                 string            `csv:"Type"`
 	Subtype              string            `csv:"Subtype"`
 	Payment              string            `csv:"Payment"`
-	Categories           string            `csv:"Categories"`
+	Categories           Categories        `csv:"Categories"`
 	ReminderInterval     string            `csv:"Reminder Interval"`
-	ReminderDistance     float64           `csv:"Reminder Distance,omitempty"`
-	Flags                string            `csv:"Flags"`
-	CurrencyCode         int               `csv:"Currency Code,omitempty"`
-	CurrencyRate         int               `csv:"Currency Rate,omitempty"`
+	ReminderDistance     Distance          `csv:"Reminder Distance,omitempty"`
+	Flags                Flags             `csv:"Flags"`
+	CurrencyCode         Currency          `csv:"Currency Code,omitempty"`
+	CurrencyRate         float64           `csv:"Currency Rate,omitempty"`
 	Latitude             float64           `csv:"Latitude,omitempty"`
 	Longitude            float64           `csv:"Longitude,omitempty"`
 	ID                   int               `csv:"ID,omitempty"`
 	NotificationInterval string            `csv:"Notification Interval"`
-	NotificationDistance float64           `csv:"Notification Distance,omitempty"`
+	NotificationDistance Distance          `csv:"Notification Distance,omitempty"`
+	Extra                map[string]string `csv:"-"`
 }
 
 // LogValue is the handler for [log.slog] to emit structured output for a
 // [MaintenanceRecord] object when logging.
 func (v MaintenanceRecord) LogValue() slog.Value {
 	return slog.GroupValue(
-		slog.Float64("odometer", v.Odometer),
+		slog.String("odometer", v.Odometer.String()),
 		slog.String("date", v.Date.Raw()),
 		slog.String("description", v.Description),
 		slog.String("location", v.Location),
-		slog.Float64("Cost", v.Cost),
+		slog.String("Cost", v.Cost.String()),
 	)
 }
 
+// decoded assigns the Currency Code to the Cost.
+func (v *MaintenanceRecord) decoded() {
+	v.Cost.Currency = v.CurrencyCode
+}
+
+// HomeCost returns the Cost converted to the home currency using the
+// record's Currency Rate.
+func (v MaintenanceRecord) HomeCost(home Currency) (Money, error) {
+	return v.Cost.Exchange(v.CurrencyRate, home)
+}
+
 // A TripRecord  is a single CSV row from the Road Trip data file and
 // represents a road trip activity with all of its associated attributes. It is
 // date and odometer range bound with a start and end value for each of those
@@ -143,25 +155,26 @@ func (v MaintenanceRecord) LogValue() slog.Value {
 type TripRecord struct {
 	Name          string            `csv:"Name"`
 	StartDate     AppStyleTimestamp `csv:"Start Date"`
-	StartOdometer float64           `csv:"Start Odometer (mi.),omitempty"`
+	StartOdometer Distance          `csv:"Start Odometer (mi.),omitempty"`
 	EndDate       AppStyleTimestamp `csv:"End Date"`
-	EndOdometer   float64           `csv:"End Odometer,omitempty"`
+	EndOdometer   Distance          `csv:"End Odometer,omitempty"`
 	Note          string            `csv:"Note"`
-	Distance      float64           `csv:"Distance,omitempty"`
+	Distance      Distance          `csv:"Distance,omitempty"`
 	ID            int               `csv:"ID,omitempty"`
 	Type          string            `csv:"Type"`
-	Categories    string            `csv:"Categories"`
-	Flags         string            `csv:"Flags"`
+	Categories    Categories        `csv:"Categories"`
+	Flags         Flags             `csv:"Flags"`
+	Extra         map[string]string `csv:"-"`
 }
 
 // LogValue is the handler for [log.slog] to emit structured output for a
 // [TripRecord] object when logging.
 func (v TripRecord) LogValue() slog.Value {
 	return slog.GroupValue(
-		slog.Float64("startOdometer", v.StartOdometer),
+		slog.String("startOdometer", v.StartOdometer.String()),
 		slog.String("startDate", v.StartDate.Raw()),
 		slog.String("note", v.Note),
-		slog.Float64("distance", v.Distance),
+		slog.String("distance", v.Distance.String()),
 	)
 }
 
@@ -170,25 +183,30 @@ func (v TripRecord) LogValue() slog.Value {
 // attributes.
 //
 // A file is expected to only contain a single row in the VEHICLE section.
+//
+// The "Key: value" lines of the Notes, such as "License Plate:" and "VIN:",
+// are extracted into Attributes.
 type VehicleRecord struct {
-	Name                string  `csv:"Name"`
-	Odometer            string  `csv:"Odometer"`
-	Units               string  `csv:"Units"`
-	Notes               string  `csv:"Notes"`
-	TankCapacity        float64 `csv:"Tank Capacity,omitempty"`
-	Tank1Units          string  `csv:"Tank Units"`
-	HomeCurrency        string  `csv:"Home Currency"`
-	Flags               string  `csv:"Flags"`
-	IconID              string  `csv:"IconID"`
-	FuelUnits           string  `csv:"FuelUnits"`
-	TripCompUnits       string  `csv:"TripComp Units"`
-	TripCompSpeed       string  `csv:"TripComp Speed"`
-	TripCompTemperature string  `csv:"TripComp Temperature"`
-	TripCompTimeEnabled string  `csv:"TripComp Time Enabled"`
-	OdometerShift       string  `csv:"Odometer Shift"`
-	Tank1Type           string  `csv:"Tank 1 Type,optional"`
-	Tank2Type           string  `csv:"Tank 2 Type,optional"`
-	Tank2Units          string  `csv:"Tank 2 Units,optional"`
+	Name                string            `csv:"Name"`
+	Odometer            string            `csv:"Odometer"`
+	Units               EconomyUnit       `csv:"Units"`
+	Notes               string            `csv:"Notes"`
+	Attributes          map[string]string `csv:"-"`
+	TankCapacity        Volume            `csv:"Tank Capacity,omitempty"`
+	Tank1Units          VolumeUnit        `csv:"Tank Units"`
+	HomeCurrency        Currency          `csv:"Home Currency"`
+	Flags               Flags             `csv:"Flags"`
+	IconID              string            `csv:"IconID"`
+	FuelUnits           string            `csv:"FuelUnits"`
+	TripCompUnits       EconomyUnit       `csv:"TripComp Units"`
+	TripCompSpeed       SpeedUnit         `csv:"TripComp Speed"`
+	TripCompTemperature TemperatureUnit   `csv:"TripComp Temperature"`
+	TripCompTimeEnabled Bool              `csv:"TripComp Time Enabled"`
+	OdometerShift       string            `csv:"Odometer Shift"`
+	Tank1Type           string            `csv:"Tank 1 Type,optional"`
+	Tank2Type           string            `csv:"Tank 2 Type,optional"`
+	Tank2Units          VolumeUnit        `csv:"Tank 2 Units,optional"`
+	Extra               map[string]string `csv:"-"`
 }
 
 // LogValue is the handler for [log.slog] to emit structured output for a
@@ -200,7 +218,63 @@ func (v VehicleRecord) LogValue() slog.Value {
 	)
 }
 
-// A VehicleRecord is a single CSV row from the Road Trip data file and
+// decoded assigns the Tank Units to the TankCapacity and extracts the
+// Attributes from the Notes.
+func (v *VehicleRecord) decoded() {
+	v.TankCapacity.Unit = v.Tank1Units
+	v.Attributes = parseNoteAttributes(v.Notes)
+}
+
+// tripCompEconomyUnit returns the unit of the trip computer's fuel economy,
+// which is the vehicle's Units when TripComp Units is not set.
+func (v VehicleRecord) tripCompEconomyUnit() EconomyUnit {
+	if v.TripCompUnits == "" {
+		return v.Units
+	}
+
+	return v.TripCompUnits
+}
+
+// DistanceUnit returns the unit the vehicle's odometer is recorded in, which
+// the app writes in the Odometer column.
+func (v VehicleRecord) DistanceUnit() (DistanceUnit, error) {
+	return ParseDistanceUnit(v.Odometer)
+}
+
+// applySettings fills in the details of the record that target points to
+// which depend on the vehicle's settings rather than on the record's own
+// columns.
+func (v VehicleRecord) applySettings(target any) {
+	// Distances in columns whose header does not name a unit are recorded
+	// in the vehicle's odometer unit.
+	if unit, err := v.DistanceUnit(); err == nil {
+		setDistanceUnits(target, unit)
+	}
+
+	switch record := target.(type) {
+	case *MaintenanceRecord:
+		setDefaultCurrency(&record.Cost, v.HomeCurrency)
+	case *ValuationRecord:
+		setDefaultCurrency(&record.Price, v.HomeCurrency)
+	case *FuelRecord:
+		// Amounts without a Currency Code are in the home currency.
+		setDefaultCurrency(&record.PricePerUnit, v.HomeCurrency)
+		setDefaultCurrency(&record.TotalPrice, v.HomeCurrency)
+
+		// The MPG column is written in the vehicle's fuel economy units,
+		// and the trip computer columns in its trip computer units.
+		record.MPG.Unit = v.Units
+		record.FuelEconomy.Unit = v.tripCompEconomyUnit()
+		record.AvgSpeed.Unit = v.TripCompSpeed
+		record.Temperature.Unit = v.TripCompTemperature
+
+		// Fill-ups without Fill Units are measured in the units of their
+		// tank.
+		if record.FillAmount.Unit == "" {
+			record.FillAmount.Unit = v.TankUnits(record.Tank())
+		}
+	}
+}
 
 // A TireRecord is a single CSV row from the Road Trip data file and represents
 // a set of tires installed on the vehicle with all of its associated
@@ -213,15 +287,16 @@ func (v VehicleRecord) LogValue() slog.Value {
 type TireRecord struct {
 	Name           string            `csv:"Name"`
 	StartDate      AppStyleTimestamp `csv:"Start Date"`
-	StartOdometer  float64           `csv:"Start Odometer (mi.),omitempty"`
-	Size           string            `csv:"Size"`
-	SizeCorrection string            `csv:"Size Correction"`
-	Distance       float64           `csv:"Distance,omitempty"`
-	Age            string            `csv:"Age"`
+	StartOdometer  Distance          `csv:"Start Odometer (mi.),omitempty"`
+	Size           TireSize          `csv:"Size"`
+	SizeCorrection Percentage        `csv:"Size Correction,omitempty"`
+	Distance       Distance          `csv:"Distance,omitempty"`
+	Age            TireAge           `csv:"Age"`
 	Note           string            `csv:"Note"`
-	Flags          string            `csv:"Flags"`
+	Flags          Flags             `csv:"Flags"`
 	ID             int               `csv:"ID,omitempty"`
 	ParentID       int               `csv:"ParentID,omitempty"`
+	Extra          map[string]string `csv:"-"`
 }
 
 // LogValue is the handler for [log.slog] to emit structured output for a
@@ -229,9 +304,10 @@ type TireRecord struct {
 func (v TireRecord) LogValue() slog.Value {
 	return slog.GroupValue(
 		slog.String("name", v.Name),
-		slog.Float64("startOdometer", v.StartOdometer),
+		slog.String("startOdometer", v.StartOdometer.String()),
 		slog.String("startDate", v.StartDate.Raw()),
-		slog.Float64("distance", v.Distance),
+		slog.String("size", v.Size.String()),
+		slog.String("distance", v.Distance.String()),
 	)
 }
 
@@ -244,10 +320,11 @@ func (v TireRecord) LogValue() slog.Value {
 type ValuationRecord struct {
 	Type     string            `csv:"Type"`
 	Date     AppStyleTimestamp `csv:"Date"`
-	Odometer float64           `csv:"Odometer,omitempty"`
-	Price    string            `csv:"Price"`
+	Odometer Distance          `csv:"Odometer,omitempty"`
+	Price    Money             `csv:"Price,omitempty"`
 	Notes    string            `csv:"Notes"`
-	Flags    string            `csv:"Flags"`
+	Flags    Flags             `csv:"Flags"`
+	Extra    map[string]string `csv:"-"`
 }
 
 // LogValue is the handler for [log.slog] to emit structured output for a
@@ -256,8 +333,15 @@ func (v ValuationRecord) LogValue() slog.Value {
 	return slog.GroupValue(
 		slog.String("type", v.Type),
 		slog.String("date", v.Date.Raw()),
-		slog.Float64("odometer", v.Odometer),
-		slog.String("price", v.Price),
-		slog.String("flags", v.Flags),
+		slog.String("odometer", v.Odometer.String()),
+		slog.String("price", v.Price.String()),
+		slog.String("flags", v.Flags.String()),
 	)
 }
+
+// HomePrice returns the Price in the home currency. Valuations are recorded
+// in the vehicle's Home Currency without an exchange rate, so a Price in any
+// other currency returns [ErrMissingCurrencyRate].
+func (v ValuationRecord) HomePrice(home Currency) (Money, error) {
+	return v.Price.Exchange(0, home)
+}
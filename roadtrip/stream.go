@@ -0,0 +1,275 @@
+package roadtrip
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Stream returns an iterator over the records of a single section of a Road
+// Trip data file read from r. The section is chosen by the record type T
+// using the same `roadtrip` struct tags as [SectionHeaderForTarget].
+//
+// Rows are read and decoded in batches of a few hundred so that very large
+// files can be processed with bounded memory, and iteration may be stopped
+// early. A row
+// which fails to decode is yielded together with its error; iteration then
+// continues with the next row unless the caller stops it. Errors reading the
+// stream itself end the iteration.
+//
+// With [VehicleOptions.Lenient] rows which fail to decode are logged instead,
+// and are yielded partially filled or skipped as when loading a [Vehicle].
+//
+// Units and currencies which a section leaves to the vehicle's settings, such
+// as the unit of the MPG column or the currency of prices without a Currency
+// Code, are only filled in when [VehicleOptions.Settings] is set. Otherwise
+// they are left empty.
+func Stream[T any](r io.Reader, options VehicleOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		decoder, err := streamSection(r, options, &[]T{})
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				yield(zero, err)
+			}
+
+			return
+		}
+
+		for {
+			var row T
+
+			err := decoder.next(&row)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if options.Settings != nil {
+				options.Settings.applySettings(&row)
+			}
+
+			var parseErr *ParseError
+			if err != nil && !errors.As(err, &parseErr) {
+				yield(row, err)
+				return
+			}
+
+			if err != nil && options.Lenient {
+				logWarnings(options.logOrDiscard(), "", err)
+
+				if !isCellError(err) {
+					continue
+				}
+
+				err = nil
+			}
+
+			if !yield(row, err) {
+				return
+			}
+		}
+	}
+}
+
+// StreamFuelRecords returns an iterator over the FUEL RECORDS section of the
+// Road Trip data file read from r. See [Stream].
+func StreamFuelRecords(r io.Reader, options VehicleOptions) iter.Seq2[FuelRecord, error] {
+	return Stream[FuelRecord](r, options)
+}
+
+// StreamMaintenanceRecords returns an iterator over the MAINTENANCE RECORDS
+// section of the Road Trip data file read from r. See [Stream].
+func StreamMaintenanceRecords(r io.Reader, options VehicleOptions) iter.Seq2[MaintenanceRecord, error] {
+	return Stream[MaintenanceRecord](r, options)
+}
+
+// StreamTrips returns an iterator over the ROAD TRIPS section of the Road
+// Trip data file read from r. See [Stream].
+func StreamTrips(r io.Reader, options VehicleOptions) iter.Seq2[TripRecord, error] {
+	return Stream[TripRecord](r, options)
+}
+
+// StreamVehicles returns an iterator over the VEHICLE section of the Road
+// Trip data file read from r. See [Stream].
+func StreamVehicles(r io.Reader, options VehicleOptions) iter.Seq2[VehicleRecord, error] {
+	return Stream[VehicleRecord](r, options)
+}
+
+// StreamTires returns an iterator over the TIRE LOG section of the Road Trip
+// data file read from r. See [Stream].
+func StreamTires(r io.Reader, options VehicleOptions) iter.Seq2[TireRecord, error] {
+	return Stream[TireRecord](r, options)
+}
+
+// StreamValuations returns an iterator over the VALUATIONS section of the
+// Road Trip data file read from r. See [Stream].
+func StreamValuations(r io.Reader, options VehicleOptions) iter.Seq2[ValuationRecord, error] {
+	return Stream[ValuationRecord](r, options)
+}
+
+// streamBatchSize is the number of rows of a streamed section which are read
+// ahead and decoded together.
+const streamBatchSize = 256
+
+// streamSection reads the Road Trip data file from r up to the section
+// identified by target and returns a [sectionDecoder] positioned at the first
+// row of that section. It returns [io.EOF] if the section is not present.
+func streamSection(r io.Reader, options VehicleOptions, target any) (*sectionDecoder, error) {
+	sectionHeader, err := SectionHeaderForTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+
+	// The preamble holds the file info line and Version,Language block and
+	// runs up to the first blank line.
+	var preamble [][]byte
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			preamble = append(preamble, line)
+		}
+
+		if err != nil || len(bytes.TrimSpace(line)) == 0 {
+			break
+		}
+	}
+
+	fileData := RawFileData(bytes.Join(preamble, nil))
+
+	delimiters, err := fileData.Delimiters()
+	if err != nil {
+		return nil, err
+	}
+
+	version, language, err := fileData.VersionInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a registered pack for the declared language every pack's
+	// headers are recognized, and the pack is chosen by the header found.
+	packs := make([]LanguagePack, 0, len(LanguagePackList()))
+
+	if pack, ok := LookupLanguagePack(language); ok {
+		packs = append(packs, pack)
+	} else {
+		for _, l := range LanguagePackList() {
+			pack, _ := LookupLanguagePack(l)
+			packs = append(packs, pack)
+		}
+	}
+
+	scanner := newSectionScanner(delimiters.Field(), packs...)
+	for _, line := range preamble {
+		scanner.scan(line)
+	}
+
+	section := &sectionReader{br: br, scanner: scanner}
+
+	header, ok, err := section.seek(sectionHeader)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, io.EOF
+	}
+
+	pack, ok := scanner.pack(header)
+	if !ok {
+		return nil, fmt.Errorf("no language pack for section header '%s'", header)
+	}
+
+	err = CheckCompatibility(version, language)
+	if err != nil {
+		if !options.Permissive {
+			return nil, err
+		}
+
+		options.logOrDiscard().Warn("Streaming unsupported Road Trip vehicle data file",
+			"section", sectionHeader,
+			"error", err,
+		)
+	}
+
+	decoder, err := newSectionDecoder(section, Section{
+		Header:     header,
+		Canonical:  sectionHeader,
+		HeaderLine: scanner.line,
+		Line:       scanner.line + 1,
+	}, target, fileFormat{
+		delimiters: delimiters,
+		pack:       pack,
+		version:    version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	decoder.batch = streamBatchSize
+	decoder.location = options.Location
+
+	return decoder, nil
+}
+
+// A sectionReader is an [io.Reader] which passes through the lines of a
+// single section of a Road Trip data file stream and reports [io.EOF] when
+// the next section header is reached.
+type sectionReader struct {
+	br      *bufio.Reader
+	scanner *sectionScanner
+	pending []byte
+	done    bool
+}
+
+// seek discards lines until the header of the wanted canonical section and
+// returns the header as written. It returns false if the stream ends first.
+func (s *sectionReader) seek(sectionHeader string) (string, bool, error) {
+	for {
+		line, err := s.br.ReadBytes('\n')
+
+		header, canonical, _ := s.scanner.scan(line)
+		if canonical == sectionHeader {
+			return header, true, nil
+		}
+
+		if errors.Is(err, io.EOF) {
+			return "", false, nil
+		} else if err != nil {
+			return "", false, err
+		}
+	}
+}
+
+// Read implements [io.Reader].
+func (s *sectionReader) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+
+		line, err := s.br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			s.done = true
+		} else if err != nil {
+			return 0, err
+		}
+
+		if header, _, _ := s.scanner.scan(line); header != "" {
+			s.done = true
+			return 0, io.EOF
+		}
+
+		s.pending = line
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+
+	return n, nil
+}
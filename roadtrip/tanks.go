@@ -0,0 +1,275 @@
+package roadtrip
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrInterleavedTanks is returned when the fuel economy of a tank cannot be
+// calculated because the other tank was filled between its fill-ups, so the
+// distance driven on each tank is not known.
+var ErrInterleavedTanks = errors.New("fill-ups of both tanks are interleaved")
+
+// A Tank identifies one of the fuel tanks of a bi-fuel or plug-in hybrid
+// vehicle. A vehicle with a single tank only has a [PrimaryTank].
+type Tank int
+
+const (
+	// PrimaryTank is the vehicle's first tank, described by the Tank Units
+	// and Tank 1 Type columns of the VEHICLE section.
+	PrimaryTank Tank = 1
+
+	// SecondaryTank is the second tank of a bi-fuel or plug-in hybrid
+	// vehicle, described by the Tank 2 Type and Tank 2 Units columns.
+	SecondaryTank Tank = 2
+)
+
+// Tank returns the tank the fill-up was made to. The app writes 0 in the
+// Tank Number column of vehicles with a single tank, so any value other than
+// 2 is the [PrimaryTank].
+func (v FuelRecord) Tank() Tank {
+	if v.TankNumber == int(SecondaryTank) {
+		return SecondaryTank
+	}
+
+	return PrimaryTank
+}
+
+// TankInfo describes one of the vehicle's fuel tanks.
+type TankInfo struct {
+	Tank Tank
+	Type string
+	Unit VolumeUnit
+}
+
+// Tanks returns the vehicle's fuel tanks. The [SecondaryTank] is only
+// included when the vehicle has a Tank 2 Type or Tank 2 Units.
+func (v VehicleRecord) Tanks() []TankInfo {
+	tanks := []TankInfo{{Tank: PrimaryTank, Type: v.Tank1Type, Unit: v.Tank1Units}}
+
+	if v.Tank2Type != "" || v.Tank2Units != "" {
+		tanks = append(tanks, TankInfo{Tank: SecondaryTank, Type: v.Tank2Type, Unit: v.Tank2Units})
+	}
+
+	return tanks
+}
+
+// TankUnits returns the units fill-ups of tank are measured in. A
+// [SecondaryTank] without Tank 2 Units is measured in the Tank Units.
+func (v VehicleRecord) TankUnits(tank Tank) VolumeUnit {
+	if tank == SecondaryTank && v.Tank2Units != "" {
+		return v.Tank2Units
+	}
+
+	return v.Tank1Units
+}
+
+// Tanks returns the vehicle's fuel tanks, or only a [PrimaryTank] of unknown
+// type if the VEHICLE section does not hold exactly one row.
+func (v Vehicle) Tanks() []TankInfo {
+	info, err := v.Info()
+	if err != nil {
+		return []TankInfo{{Tank: PrimaryTank}}
+	}
+
+	return info.Tanks()
+}
+
+// TankFuelRecords returns the fill-ups made to tank, in odometer order.
+func (v Vehicle) TankFuelRecords(tank Tank) []FuelRecord {
+	return v.fuelRecordsFunc(func(fuel FuelRecord) bool {
+		return fuel.Tank() == tank
+	})
+}
+
+// fuelRecordsFunc returns the fill-ups for which match returns true, in
+// odometer order.
+func (v Vehicle) fuelRecordsFunc(match func(FuelRecord) bool) []FuelRecord {
+	var records []FuelRecord
+
+	for _, fuel := range v.FuelRecords {
+		if match(fuel) {
+			records = append(records, fuel)
+		}
+	}
+
+	slices.SortStableFunc(records, func(a, b FuelRecord) int {
+		return cmp.Compare(a.Odometer.Value, b.Odometer.Value)
+	})
+
+	return records
+}
+
+// checkInterleaved returns [ErrInterleavedTanks] if a fill-up for which
+// match returns false was made between the first and last of records, which
+// are in odometer order, so the distance between them was partly driven on
+// another tank.
+func (v Vehicle) checkInterleaved(records []FuelRecord, match func(FuelRecord) bool) error {
+	if len(records) < 2 { //nolint:mnd // a first and a last fill-up
+		return nil
+	}
+
+	first, last := records[0].Odometer.Value, records[len(records)-1].Odometer.Value
+
+	for _, fuel := range v.FuelRecords {
+		if !match(fuel) && fuel.Odometer.Value > first && fuel.Odometer.Value < last {
+			return fmt.Errorf("%w: tank %d filled at %v", ErrInterleavedTanks, fuel.Tank(), fuel.Odometer)
+		}
+	}
+
+	return nil
+}
+
+// tankFuelEconomy implements [Vehicle.TankFuelEconomy] in unit.
+func (v Vehicle) tankFuelEconomy(tank Tank, unit EconomyUnit) (FuelEconomy, error) {
+	match := func(fuel FuelRecord) bool {
+		return fuel.Tank() == tank
+	}
+
+	records := v.fuelRecordsFunc(match)
+
+	if err := v.checkInterleaved(records, match); err != nil {
+		return FuelEconomy{}, err
+	}
+
+	return averageFuelEconomy(records, unit)
+}
+
+// TankEconomyUnit returns the unit the fuel economy of tank is expressed in.
+// This is the vehicle's [Vehicle.EconomyUnit] when it measures the same kind
+// of fuel as the tank, so that the electric tank of a plug-in hybrid is not
+// measured in MPG, and otherwise a unit matching the tank's units and the
+// vehicle's odometer.
+func (v Vehicle) TankEconomyUnit(tank Tank) EconomyUnit {
+	unit := v.EconomyUnit()
+
+	info, err := v.Info()
+	if err != nil {
+		return unit
+	}
+
+	volume := info.TankUnits(tank)
+	if volume == "" || volume.IsEnergy() == unit.IsEnergy() {
+		return unit
+	}
+
+	metric := v.DistanceUnit() == Kilometers
+
+	switch {
+	case volume.IsEnergy() && metric:
+		return KilowattHoursPer100Kilometers
+	case volume.IsEnergy():
+		return MilesPerKilowattHour
+	case volume == ImperialGallons:
+		return MilesPerImperialGallon
+	case metric || volume == Liters:
+		return LitersPer100Kilometers
+	default:
+		return MilesPerGallon
+	}
+}
+
+// TankFuelEconomy returns the fuel economy of tank over its fill-ups, in the
+// tank's [Vehicle.TankEconomyUnit]. It is calculated like
+// [Vehicle.AverageFuelEconomy] from the distance between the tank's full
+// fill-ups. The log does not record which tank each mile was driven on, so
+// [ErrInterleavedTanks] is returned if the other tank was filled in between.
+func (v Vehicle) TankFuelEconomy(tank Tank) (FuelEconomy, error) {
+	return v.tankFuelEconomy(tank, v.TankEconomyUnit(tank))
+}
+
+// TankFuelVolume returns the total volume of the fill-ups made to tank, in
+// the tank's units.
+func (v Vehicle) TankFuelVolume(tank Tank) (Volume, error) {
+	total := Volume{Unit: v.tankUnits(tank)}
+
+	for _, fuel := range v.TankFuelRecords(tank) {
+		var err error
+
+		total, err = total.Add(fuel.FillAmount)
+		if err != nil {
+			return Volume{}, err
+		}
+	}
+
+	return total, nil
+}
+
+// TankFuelCost returns the total price of the fill-ups made to tank,
+// converted to the vehicle's [Vehicle.HomeCurrency].
+func (v Vehicle) TankFuelCost(tank Tank) (Money, error) {
+	return v.fuelCost(v.TankFuelRecords(tank))
+}
+
+// tankUnits returns the units of tank, or the units of its first fill-up if
+// the VEHICLE section does not hold exactly one row.
+func (v Vehicle) tankUnits(tank Tank) VolumeUnit {
+	if info, err := v.Info(); err == nil {
+		return info.TankUnits(tank)
+	}
+
+	if records := v.TankFuelRecords(tank); len(records) > 0 {
+		return records[0].FillAmount.Unit
+	}
+
+	return ""
+}
+
+// A TankSummary totals the fill-ups made to one of the vehicle's tanks.
+// Volumes are only ever totalled per tank, so kilowatt-hours are never
+// added to gallons or liters.
+type TankSummary struct {
+	TankInfo
+
+	FillUps int
+	Volume  Volume
+	Cost    Money
+
+	// Economy is the tank's [Vehicle.TankFuelEconomy], which is zero when
+	// it cannot be calculated.
+	Economy FuelEconomy
+
+	// EconomyErr is the reason Economy could not be calculated, which is
+	// [ErrNotEnoughFuelRecords] when the tank has fewer than two fill-ups
+	// and [ErrInterleavedTanks] when its fill-ups are interleaved with those
+	// of the other tank. It is nil when Economy is set.
+	EconomyErr error
+}
+
+// TankSummaries returns a [TankSummary] for each of the vehicle's tanks.
+func (v Vehicle) TankSummaries() ([]TankSummary, error) {
+	tanks := v.Tanks()
+	summaries := make([]TankSummary, 0, len(tanks))
+
+	for _, tank := range tanks {
+		summary := TankSummary{
+			TankInfo: tank,
+			FillUps:  len(v.TankFuelRecords(tank.Tank)),
+		}
+
+		var err error
+
+		summary.Volume, err = v.TankFuelVolume(tank.Tank)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Cost, err = v.TankFuelCost(tank.Tank)
+		if err != nil {
+			return nil, err
+		}
+
+		summary.Economy, err = v.TankFuelEconomy(tank.Tank)
+		if errors.Is(err, ErrNotEnoughFuelRecords) || errors.Is(err, ErrInterleavedTanks) {
+			summary.EconomyErr = err
+		} else if err != nil {
+			return nil, err
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,148 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// appDateTimeLayout and appDateLayout are the layouts the app writes
+	// timestamps and dates in.
+	appDateTimeLayout = "2006-1-2 15:04"
+	appDateLayout     = "2006-1-2"
+)
+
+// ErrInvalidTimestamp is returned when a timestamp is not in a format the app
+// writes.
+var ErrInvalidTimestamp = errors.New("unable to parse date")
+
+// AppStyleTimestamp contains a date or timestamp formatted the way the app
+// writes them.
+//
+// The iOS app uses an awkward time formatting style that is inconsistent
+// and challenging to parse. We abstract it into an interface to make it
+// simpler to massage and work with the data as it arrives.
+//
+// The app writes local wall clock times without a zone, so timestamps are
+// interpreted in the [time.Location] set by [VehicleOptions], or in UTC when
+// none is set. Some columns hold only a date, which [AppStyleTimestamp.HasTime]
+// reports.
+type AppStyleTimestamp struct {
+	raw      string
+	location *time.Location
+}
+
+// NewAppStyleTimestamp returns the timestamp written as raw, to be interpreted
+// in location. A nil location is UTC.
+func NewAppStyleTimestamp(raw string, location *time.Location) AppStyleTimestamp {
+	return AppStyleTimestamp{raw: raw, location: location}
+}
+
+// Raw returns the timestamp as written in the data file.
+func (ts AppStyleTimestamp) Raw() string {
+	return ts.raw
+}
+
+// String returns the timestamp as written in the data file.
+func (ts AppStyleTimestamp) String() string {
+	return ts.raw
+}
+
+// Location returns the time zone the timestamp is interpreted in.
+func (ts AppStyleTimestamp) Location() *time.Location {
+	if ts.location == nil {
+		return time.UTC
+	}
+
+	return ts.location
+}
+
+// In returns the same timestamp interpreted in location.
+func (ts AppStyleTimestamp) In(location *time.Location) AppStyleTimestamp {
+	ts.location = location
+	return ts
+}
+
+// HasTime reports whether the timestamp includes a time of day. It is false
+// for date-only values such as "2023-3-20" and for values which cannot be
+// parsed.
+func (ts AppStyleTimestamp) HasTime() bool {
+	_, err := time.Parse(appDateTimeLayout, strings.TrimSpace(ts.raw))
+	return err == nil
+}
+
+// Parse turns a Road Trip app styled timestamp string into a proper Go
+// [time.Time] value in the timestamp's [AppStyleTimestamp.Location]. A
+// date-only value is midnight at the start of that day. If parsing fails it
+// will return an error wrapping [ErrInvalidTimestamp].
+func (ts AppStyleTimestamp) Parse() (time.Time, error) {
+	raw := strings.TrimSpace(ts.raw)
+
+	for _, layout := range []string{appDateTimeLayout, appDateLayout} {
+		t, err := time.ParseInLocation(layout, raw, ts.Location())
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w '%s'", ErrInvalidTimestamp, ts.raw)
+}
+
+// MustParse is like [AppStyleTimestamp.Parse] but panics if the timestamp
+// cannot be parsed.
+func (ts AppStyleTimestamp) MustParse() time.Time {
+	t, err := ts.Parse()
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// Time turns a Road Trip app styled timestamp string into a proper Go
+// [time.Time] value. Unlike Parse, Time will silently return the zero
+// [time.Time] if it is given malformed or unexpected data.
+func (ts AppStyleTimestamp) Time() time.Time {
+	t, _ := ts.Parse()
+	return t
+}
+
+// MarshalText implements [encoding.TextMarshaler], returning the timestamp as
+// written in the data file.
+func (ts AppStyleTimestamp) MarshalText() ([]byte, error) {
+	return []byte(ts.raw), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. The text is kept as
+// written and is only validated when parsed, so that a malformed date does
+// not prevent the rest of its record from being read. The location is left
+// unchanged.
+func (ts *AppStyleTimestamp) UnmarshalText(text []byte) error {
+	ts.raw = string(text)
+	return nil
+}
+
+// setLocation assigns location to every [AppStyleTimestamp] field of the
+// record that target points to. A nil location leaves the fields in UTC.
+func setLocation(target any, location *time.Location) {
+	if location == nil {
+		return
+	}
+
+	record := reflect.ValueOf(target).Elem()
+	timestampType := reflect.TypeFor[AppStyleTimestamp]()
+
+	for i := range record.NumField() {
+		field := record.Field(i)
+		if field.Type() != timestampType || !field.CanSet() {
+			continue
+		}
+
+		if ts, ok := field.Addr().Interface().(*AppStyleTimestamp); ok {
+			ts.location = location
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package roadtrip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// millimetersPerInch converts rim diameters to millimeters.
+	millimetersPerInch = 25.4
+
+	// percent is the scale of a percentage.
+	percent = 100
+)
+
+var (
+	// ErrInvalidTireSize is returned when a tire size is not a metric size
+	// such as "P225/45R17".
+	ErrInvalidTireSize = errors.New("invalid tire size")
+
+	// ErrInvalidTireAge is returned when a tire age is not written as years
+	// and months, such as "1 year 10 months".
+	ErrInvalidTireAge = errors.New("invalid tire age")
+)
+
+// tireSizePattern matches a metric tire size: an optional service type, the
+// section width in millimeters, the aspect ratio, the construction and the
+// rim diameter in inches. Any load index and speed rating which follow are
+// ignored.
+var tireSizePattern = regexp.MustCompile(
+	`(?i)^(P|LT|ST|T)?\s*(\d{3})\s*/\s*(\d{2,3})\s*(ZR|R|D|B|-)?\s*(\d{2}(?:\.\d)?)\b`,
+)
+
+// A TireSize is a metric tire size such as "P225/45R17". Sizes which cannot
+// be parsed keep only their Raw text, and [TireSize.IsValid] reports false.
+type TireSize struct {
+	Raw          string
+	Service      string
+	Width        int
+	AspectRatio  int
+	Construction string
+	RimDiameter  float64
+}
+
+// ParseTireSize returns the [TireSize] for a metric size such as "P225/45R17"
+// or "245/40ZR18 97Y".
+func ParseTireSize(s string) (TireSize, error) {
+	m := tireSizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return TireSize{Raw: s}, fmt.Errorf("%w '%s'", ErrInvalidTireSize, s)
+	}
+
+	width, _ := strconv.Atoi(m[2])
+	aspect, _ := strconv.Atoi(m[3])
+	rim, _ := strconv.ParseFloat(m[5], 64)
+
+	return TireSize{
+		Raw:          s,
+		Service:      strings.ToUpper(m[1]),
+		Width:        width,
+		AspectRatio:  aspect,
+		Construction: strings.ToUpper(m[4]),
+		RimDiameter:  rim,
+	}, nil
+}
+
+// IsValid reports whether the size was parsed into its dimensions.
+func (s TireSize) IsValid() bool {
+	return s.Width > 0 && s.RimDiameter > 0
+}
+
+// SidewallHeight returns the height of the sidewall in millimeters.
+func (s TireSize) SidewallHeight() float64 {
+	return float64(s.Width*s.AspectRatio) / percent
+}
+
+// Diameter returns the overall diameter of the tire in millimeters.
+func (s TireSize) Diameter() float64 {
+	return s.RimDiameter*millimetersPerInch + 2*s.SidewallHeight()
+}
+
+// DiameterDifference returns the percentage by which the diameter of the tire
+// differs from the original size. A positive difference means the tire is
+// larger, so the vehicle travels further than its speedometer and odometer
+// report by that percentage.
+func (s TireSize) DiameterDifference(original TireSize) (float64, error) {
+	if !s.IsValid() || !original.IsValid() {
+		return 0, fmt.Errorf("%w: cannot compare '%s' with '%s'", ErrInvalidTireSize, s.Raw, original.Raw)
+	}
+
+	return (s.Diameter()/original.Diameter() - 1) * percent, nil
+}
+
+// String returns the size as written in the data file.
+func (s TireSize) String() string {
+	return s.Raw
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. A size which cannot be
+// parsed is kept as written.
+func (s *TireSize) UnmarshalCSV(data []byte) error {
+	*s, _ = ParseTireSize(string(data))
+	return nil
+}
+
+// A Percentage is a numeric percentage such as the Size Correction of a
+// [TireRecord]. A trailing percent sign is accepted.
+type Percentage float64
+
+// Factor returns the multiplier the percentage applies, such as 1.02 for 2%.
+func (p Percentage) Factor() float64 {
+	return 1 + float64(p)/percent
+}
+
+// String returns the percentage with a percent sign, such as "2.5%".
+func (p Percentage) String() string {
+	return strconv.FormatFloat(float64(p), 'f', -1, 64) + "%"
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler].
+func (p *Percentage) UnmarshalCSV(data []byte) error {
+	value, err := parseCSVFloat(bytes.TrimSuffix(bytes.TrimSpace(data), []byte("%")))
+	if err != nil {
+		return err
+	}
+
+	*p = Percentage(value)
+
+	return nil
+}
+
+// A TireAge is the age of a set of tires as written by the app, such as
+// "1 year 10 months". Ages which cannot be parsed keep only their Raw text.
+type TireAge struct {
+	Raw    string
+	Years  int
+	Months int
+}
+
+// ParseTireAge returns the [TireAge] for an age written as years and months,
+// such as "1 year 10 months", "2 years" or "1 month".
+func ParseTireAge(s string) (TireAge, error) {
+	age := TireAge{Raw: s}
+
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields)%2 != 0 {
+		return TireAge{Raw: s}, fmt.Errorf("%w '%s'", ErrInvalidTireAge, s)
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return TireAge{Raw: s}, fmt.Errorf("%w '%s'", ErrInvalidTireAge, s)
+		}
+
+		switch strings.TrimSuffix(fields[i+1], ",") {
+		case "year", "years", "yr", "yrs", "an", "ans":
+			age.Years += n
+		case "month", "months", "mo", "mos", "mois":
+			age.Months += n
+		default:
+			return TireAge{Raw: s}, fmt.Errorf("%w '%s'", ErrInvalidTireAge, s)
+		}
+	}
+
+	return age, nil
+}
+
+// TotalMonths returns the age in months.
+func (a TireAge) TotalMonths() int {
+	return a.Years*12 + a.Months //nolint:mnd // months in a year
+}
+
+// AddTo returns the time which is the age after t, such as the date a set
+// of tires installed at t reached this age.
+func (a TireAge) AddTo(t time.Time) time.Time {
+	return t.AddDate(a.Years, a.Months, 0)
+}
+
+// String returns the age in the form the app writes it, such as
+// "1 year 10 months".
+func (a TireAge) String() string {
+	var parts []string
+
+	if a.Years > 0 {
+		parts = append(parts, plural(a.Years, "year"))
+	}
+
+	if a.Months > 0 || a.Years == 0 {
+		parts = append(parts, plural(a.Months, "month"))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// plural returns n followed by unit, pluralized when n is not one.
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+
+	return strconv.Itoa(n) + " " + unit + "s"
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. An empty cell is a zero
+// age, and an age which cannot be parsed is kept as written.
+func (a *TireAge) UnmarshalCSV(data []byte) error {
+	*a, _ = ParseTireAge(string(data))
+	return nil
+}
+
+// CorrectedDistance returns the distance travelled on the tires adjusted by
+// their Size Correction, for tires whose size differs from the one the
+// vehicle's odometer is calibrated for.
+func (v TireRecord) CorrectedDistance() Distance {
+	return Distance{Value: v.Distance.Value * v.SizeCorrection.Factor(), Unit: v.Distance.Unit}
+}
@@ -0,0 +1,257 @@
+package roadtrip
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// A Section describes the location of a single section block within a Road
+// Trip data file.
+type Section struct {
+	// Header is the section header exactly as written in the file.
+	Header string
+
+	// Canonical is the canonical (English) section header which Header maps
+	// to through the file's [LanguagePack]. It is empty for sections which
+	// this package does not recognize.
+	Canonical string
+
+	// HeaderLine is the 1-based line number of the section header line.
+	HeaderLine int
+
+	// Line is the 1-based line number of the first line of the section
+	// contents, which is the CSV column header row.
+	Line int
+
+	// Start and End are the byte offsets of the section contents within the
+	// raw file data. Blank lines trailing the section are excluded.
+	Start int
+	End   int
+}
+
+// Sections tokenizes the raw file data line by line and returns every
+// section it finds in file order.
+//
+// A line is only recognized as a section header when it consists solely of an
+// all-caps header, follows a blank line, and is not inside a quoted CSV
+// field. This keeps multi-line notes which happen to contain header text
+// from splitting the file in the wrong place. Headers which are not known to
+// the file's [LanguagePack] are returned with an empty Canonical header.
+func (fileData *RawFileData) Sections() []Section {
+	return fileData.sections(fileData.fieldDelimiter(), fileData.languagePackOrDefault())
+}
+
+// sections implements [RawFileData.Sections] for a file whose fields are
+// separated by field and whose section headers are written in the language of
+// pack.
+func (fileData *RawFileData) sections(field rune, pack LanguagePack) []Section {
+	var (
+		sections   []Section
+		data       = *fileData
+		scanner    = newSectionScanner(field, pack)
+		offset     int
+		contentEnd int
+	)
+
+	closeSection := func() {
+		if len(sections) > 0 {
+			sections[len(sections)-1].End = max(contentEnd, sections[len(sections)-1].Start)
+		}
+	}
+
+	for offset < len(data) {
+		end := len(data)
+		if i := bytes.IndexByte(data[offset:], '\n'); i >= 0 {
+			end = offset + i + 1
+		}
+
+		header, canonical, blank := scanner.scan(data[offset:end])
+
+		switch {
+		case header != "":
+			closeSection()
+
+			sections = append(sections, Section{
+				Header:     header,
+				Canonical:  canonical,
+				HeaderLine: scanner.line,
+				Line:       scanner.line + 1,
+				Start:      end,
+			})
+
+			contentEnd = end
+		case !blank:
+			contentEnd = end
+		}
+
+		offset = end
+	}
+
+	closeSection()
+
+	return sections
+}
+
+// A sectionScanner classifies the lines of a Road Trip data file one at a
+// time, tracking just enough state to recognize section header lines.
+type sectionScanner struct {
+	known     map[string]string
+	packs     map[string]LanguagePack
+	field     rune
+	line      int
+	inQuotes  bool
+	prevBlank bool
+}
+
+// newSectionScanner returns a [sectionScanner] for lines separated into
+// fields by field which recognizes the section headers of the given language
+// packs.
+func newSectionScanner(field rune, packs ...LanguagePack) *sectionScanner {
+	s := &sectionScanner{
+		known: make(map[string]string),
+		packs: make(map[string]LanguagePack),
+		field: field,
+	}
+
+	for _, pack := range packs {
+		for header, canonical := range pack.sectionHeaders() {
+			s.known[header] = canonical
+			s.packs[header] = pack
+		}
+	}
+
+	return s
+}
+
+// pack returns the language pack which recognized a section header returned
+// by [sectionScanner.scan], or false for an unrecognized header.
+func (s *sectionScanner) pack(header string) (LanguagePack, bool) {
+	pack, ok := s.packs[header]
+
+	return pack, ok
+}
+
+// scan consumes a single raw line, including its line terminator. If the
+// line is a section header it returns the header as written and its
+// canonical equivalent, which is empty for unrecognized headers. It also
+// reports whether the line is blank and outside of any quoted field.
+func (s *sectionScanner) scan(raw []byte) (string, string, bool) {
+	s.line++
+
+	text := string(bytes.TrimRight(raw, "\r\n"))
+
+	if canonical, ok := s.known[text]; (ok || isHeaderLine(text)) && s.prevBlank && !s.inQuotes {
+		s.prevBlank = false
+
+		return text, canonical, false
+	}
+
+	blank := !s.inQuotes && text == ""
+
+	// The file info line quotes its delimiters in the middle of a field.
+	if s.line > 1 || !strings.HasPrefix(strings.TrimPrefix(text, "\ufeff"), FileInfoPrefix) {
+		s.trackQuotes(text)
+	}
+
+	s.prevBlank = blank
+
+	return "", "", blank
+}
+
+// trackQuotes follows the quoted fields of a line as RFC 4180 defines them:
+// a quote only opens a quoted field at the start of the field, and a doubled
+// quote within a quoted field is an escaped quote. Stray quotes in the middle
+// of an unquoted field, as found in corrupted rows, are ignored.
+func (s *sectionScanner) trackQuotes(text string) {
+	fieldStart := !s.inQuotes
+	closed := false
+
+	for _, r := range text {
+		switch {
+		case r == '"' && s.inQuotes:
+			s.inQuotes = false
+			closed = true
+			fieldStart = false
+
+			continue
+		case r == '"' && (closed || fieldStart):
+			s.inQuotes = true
+			fieldStart = false
+		case r == '"':
+			fieldStart = false
+		default:
+			fieldStart = !s.inQuotes && r == s.field
+		}
+
+		closed = false
+	}
+}
+
+// isHeaderLine reports whether text has the form of a section header, which
+// is written in capital letters with no field delimiters or quotes.
+func isHeaderLine(text string) bool {
+	hasLetter := false
+
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r):
+			if !unicode.IsUpper(r) {
+				return false
+			}
+
+			hasLetter = true
+		case unicode.IsDigit(r), r == ' ', r == '-', r == '_', r == '/', r == '&':
+		default:
+			return false
+		}
+	}
+
+	return hasLetter
+}
+
+// Section returns the location of the section with the given canonical
+// section header and whether it is present in the raw file data.
+func (fileData *RawFileData) Section(sectionHeader string) (Section, bool) {
+	return findSection(fileData.Sections(), sectionHeader)
+}
+
+// findSection returns the section of sections with the given canonical
+// section header and whether it is present.
+func findSection(sections []Section, sectionHeader string) (Section, bool) {
+	for _, section := range sections {
+		if section.Canonical == sectionHeader {
+			return section, true
+		}
+	}
+
+	return Section{}, false
+}
+
+// A fileLayout holds the sections and format of a data file, so that a file
+// is only tokenized once however many of its sections are decoded.
+type fileLayout struct {
+	sections []Section
+	format   fileFormat
+}
+
+// layout tokenizes the raw file data and resolves its format. A fragment
+// without a Version,Language block is treated as an unknown version.
+func (fileData *RawFileData) layout() (fileLayout, error) {
+	delimiters, err := fileData.Delimiters()
+	if err != nil {
+		return fileLayout{}, err
+	}
+
+	version, _, _ := fileData.VersionInfo()
+	pack := fileData.languagePackOrDefault()
+
+	return fileLayout{
+		sections: fileData.sections(delimiters.Field(), pack),
+		format: fileFormat{
+			delimiters: delimiters,
+			pack:       pack,
+			version:    version,
+		},
+	}, nil
+}
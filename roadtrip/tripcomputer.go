@@ -0,0 +1,239 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A SpeedUnit identifies the unit in which a [Speed] is measured.
+type SpeedUnit string
+
+const (
+	// MilesPerHour is the unit written by Road Trip as "MPH".
+	MilesPerHour SpeedUnit = "MPH"
+
+	// KilometersPerHour is the unit written by Road Trip as "km/h".
+	KilometersPerHour SpeedUnit = "km/h"
+)
+
+// A TemperatureUnit identifies the scale in which a [Temperature] is measured.
+type TemperatureUnit string
+
+const (
+	// Celsius is the scale written by Road Trip as "C".
+	Celsius TemperatureUnit = "C"
+
+	// Fahrenheit is the scale written by Road Trip as "F".
+	Fahrenheit TemperatureUnit = "F"
+
+	// fahrenheitScale and fahrenheitOffset convert between the two scales.
+	fahrenheitScale  = 1.8
+	fahrenheitOffset = 32
+)
+
+var (
+	// ErrUnknownSpeedUnit is returned when a speed unit is not recognized.
+	ErrUnknownSpeedUnit = errors.New("unknown speed unit")
+
+	// ErrUnknownTemperatureUnit is returned when a temperature scale is not
+	// recognized.
+	ErrUnknownTemperatureUnit = errors.New("unknown temperature unit")
+
+	// ErrInvalidDuration is returned when a drive time is not written as
+	// hours and minutes.
+	ErrInvalidDuration = errors.New("invalid duration")
+)
+
+// ParseSpeedUnit returns the [SpeedUnit] for a unit as written in the TripComp
+// Speed column of the VEHICLE section, such as "MPH" or "km/h".
+func ParseSpeedUnit(s string) (SpeedUnit, error) {
+	switch strings.ToLower(strings.Join(strings.Fields(s), "")) {
+	case "mph", "mi/h":
+		return MilesPerHour, nil
+	case "km/h", "kmh", "kph":
+		return KilometersPerHour, nil
+	default:
+		return "", fmt.Errorf("%w '%s'", ErrUnknownSpeedUnit, s)
+	}
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Recognized units are
+// normalized to the constants of this package, and any other value is kept
+// as written.
+func (u *SpeedUnit) UnmarshalCSV(data []byte) error {
+	unit, err := ParseSpeedUnit(string(data))
+	if err != nil {
+		unit = SpeedUnit(data)
+	}
+
+	*u = unit
+
+	return nil
+}
+
+// ParseTemperatureUnit returns the [TemperatureUnit] for a scale as written in
+// the TripComp Temperature column of the VEHICLE section, such as "C" or "F".
+func ParseTemperatureUnit(s string) (TemperatureUnit, error) {
+	switch strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "°")) {
+	case "c", "celsius":
+		return Celsius, nil
+	case "f", "fahrenheit":
+		return Fahrenheit, nil
+	default:
+		return "", fmt.Errorf("%w '%s'", ErrUnknownTemperatureUnit, s)
+	}
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Recognized scales are
+// normalized to the constants of this package, and any other value is kept
+// as written.
+func (u *TemperatureUnit) UnmarshalCSV(data []byte) error {
+	unit, err := ParseTemperatureUnit(string(data))
+	if err != nil {
+		unit = TemperatureUnit(data)
+	}
+
+	*u = unit
+
+	return nil
+}
+
+// A Speed is an average speed reported by the trip computer together with the
+// unit it was recorded in, which is taken from the TripComp Speed setting of
+// the [VehicleRecord].
+type Speed struct {
+	Value float64
+	Unit  SpeedUnit
+}
+
+// In returns the speed converted to unit. Only [MilesPerHour] and
+// [KilometersPerHour] convert between each other; a speed in or to any other
+// unit, or without one, is returned unchanged in its own unit.
+func (s Speed) In(unit SpeedUnit) Speed {
+	switch {
+	case s.Unit == MilesPerHour && unit == KilometersPerHour:
+		return Speed{Value: s.Value * kilometersPerMile, Unit: unit}
+	case s.Unit == KilometersPerHour && unit == MilesPerHour:
+		return Speed{Value: s.Value / kilometersPerMile, Unit: unit}
+	default:
+		return s
+	}
+}
+
+// String returns the speed and its unit, such as "42.5 MPH".
+func (s Speed) String() string {
+	return formatQuantity(s.Value, string(s.Unit))
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Only the value is read
+// from the cell, the unit is assigned from the vehicle's TripComp Speed.
+func (s *Speed) UnmarshalCSV(data []byte) error {
+	value, err := parseCSVFloat(data)
+	if err != nil {
+		return err
+	}
+
+	s.Value = value
+
+	return nil
+}
+
+// A Temperature is an outside temperature reported by the trip computer
+// together with its scale, which is taken from the TripComp Temperature
+// setting of the [VehicleRecord].
+type Temperature struct {
+	Value float64
+	Unit  TemperatureUnit
+}
+
+// In returns the temperature converted to unit. Only [Celsius] and
+// [Fahrenheit] convert between each other; a temperature in or to any other
+// scale, or without one, is returned unchanged in its own scale.
+func (t Temperature) In(unit TemperatureUnit) Temperature {
+	switch {
+	case t.Unit == Celsius && unit == Fahrenheit:
+		return Temperature{Value: t.Value*fahrenheitScale + fahrenheitOffset, Unit: unit}
+	case t.Unit == Fahrenheit && unit == Celsius:
+		return Temperature{Value: (t.Value - fahrenheitOffset) / fahrenheitScale, Unit: unit}
+	default:
+		return t
+	}
+}
+
+// String returns the temperature and its scale, such as "21.5 C".
+func (t Temperature) String() string {
+	return formatQuantity(t.Value, string(t.Unit))
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Only the value is read
+// from the cell, the scale is assigned from the vehicle's TripComp
+// Temperature.
+func (t *Temperature) UnmarshalCSV(data []byte) error {
+	value, err := parseCSVFloat(data)
+	if err != nil {
+		return err
+	}
+
+	t.Value = value
+
+	return nil
+}
+
+// A Duration is a drive time reported by the trip computer. It embeds a
+// [time.Duration], so methods such as Hours and Minutes can be called on it
+// directly. Drive times which cannot be parsed keep only their Raw text and
+// a zero Duration.
+type Duration struct {
+	time.Duration
+
+	Raw string
+}
+
+// ParseDuration returns the [Duration] for a drive time written as hours and
+// minutes such as "1:25", optionally followed by seconds. Go duration
+// strings such as "1h25m" are also accepted, and an empty string is zero.
+func ParseDuration(s string) (Duration, error) {
+	text := strings.TrimSpace(s)
+	if text == "" {
+		return Duration{Raw: s}, nil
+	}
+
+	if !strings.Contains(text, ":") {
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return Duration{Raw: s}, fmt.Errorf("%w '%s'", ErrInvalidDuration, text)
+		}
+
+		return Duration{Duration: duration, Raw: s}, nil
+	}
+
+	parts := strings.Split(text, ":")
+	if len(parts) > 3 { //nolint:mnd // hours, minutes and seconds
+		return Duration{Raw: s}, fmt.Errorf("%w '%s'", ErrInvalidDuration, text)
+	}
+
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+
+	var duration time.Duration
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Duration{Raw: s}, fmt.Errorf("%w '%s'", ErrInvalidDuration, text)
+		}
+
+		duration += time.Duration(n) * units[i]
+	}
+
+	return Duration{Duration: duration, Raw: s}, nil
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. A drive time which cannot
+// be parsed is kept as written.
+func (d *Duration) UnmarshalCSV(data []byte) error {
+	*d, _ = ParseDuration(string(data))
+	return nil
+}
@@ -0,0 +1,78 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoVehicle is returned when a data file has no row in its VEHICLE
+	// section.
+	ErrNoVehicle = errors.New("no VEHICLE row")
+
+	// ErrMultipleVehicles is returned when a data file has more than one
+	// row in its VEHICLE section.
+	ErrMultipleVehicles = errors.New("multiple VEHICLE rows")
+)
+
+// A VehicleCountError is returned by [Vehicle.Info] when the VEHICLE section
+// does not hold exactly one row. It wraps [ErrNoVehicle] or
+// [ErrMultipleVehicles].
+type VehicleCountError struct {
+	Filename string
+	Count    int
+}
+
+// Error implements the error interface.
+func (e *VehicleCountError) Error() string {
+	if e.Filename == "" {
+		return fmt.Sprintf("%s: found %d, want 1", e.Unwrap(), e.Count)
+	}
+
+	return fmt.Sprintf("%s: %s: found %d, want 1", e.Filename, e.Unwrap(), e.Count)
+}
+
+// Unwrap returns [ErrNoVehicle] or [ErrMultipleVehicles].
+func (e *VehicleCountError) Unwrap() error {
+	if e.Count == 0 {
+		return ErrNoVehicle
+	}
+
+	return ErrMultipleVehicles
+}
+
+// Info returns the single row of the VEHICLE section, which holds the name
+// and settings of the vehicle. It returns a [*VehicleCountError] if the
+// section is missing or holds more than one row.
+func (v Vehicle) Info() (VehicleRecord, error) {
+	if len(v.Vehicles) != 1 {
+		return VehicleRecord{}, &VehicleCountError{Filename: v.Filename, Count: len(v.Vehicles)}
+	}
+
+	return v.Vehicles[0], nil
+}
+
+// Name returns the name of the vehicle, or an empty string if the VEHICLE
+// section does not hold exactly one row.
+func (v Vehicle) Name() string {
+	info, _ := v.Info()
+
+	return info.Name
+}
+
+// DistanceUnit returns the unit the vehicle's odometer is recorded in, or an
+// empty DistanceUnit if it is not known.
+func (v Vehicle) DistanceUnit() DistanceUnit {
+	info, _ := v.Info()
+	unit, _ := info.DistanceUnit()
+
+	return unit
+}
+
+// TankCapacity returns the capacity of the vehicle's fuel tank, or a zero
+// Volume if it is not known.
+func (v Vehicle) TankCapacity() Volume {
+	info, _ := v.Info()
+
+	return info.TankCapacity
+}
@@ -0,0 +1,217 @@
+package roadtrip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrMissingVersion is returned when the Version,Language block cannot
+	// be found at the top of a Road Trip data file.
+	ErrMissingVersion = errors.New("missing Road Trip Version,Language block")
+
+	// ErrUnsupportedVersion is returned when the data file declares a
+	// Version which is not present in the compatibility table.
+	ErrUnsupportedVersion = errors.New("unsupported Road Trip data file version")
+
+	// ErrUnsupportedLanguage is returned when the data file declares a
+	// Language which is not supported for its Version.
+	ErrUnsupportedLanguage = errors.New("unsupported Road Trip data file language")
+)
+
+// VersionSupport describes how this package handles a single Road Trip data
+// file version.
+type VersionSupport struct {
+	// Version is the numeric file version declared in the data file.
+	Version int
+
+	// ColumnFixups lists the known mismatches between the header row and
+	// the data rows of the sections this version writes.
+	ColumnFixups []ColumnFixup
+}
+
+// A ColumnFixup describes columns which the app writes in the header row of
+// a section without writing a value for them in the data rows. A fix-up is
+// only applied when the section's data rows have fewer fields than its
+// header row, and removing the columns makes the counts agree.
+type ColumnFixup struct {
+	// Section is the canonical header of the affected section.
+	Section string
+
+	// Columns are the canonical names of the header columns which have no
+	// data, in the order they are written.
+	Columns []string
+}
+
+// compatibilityTable lists every Road Trip data file version this package
+// knows how to parse. When a new app release changes the columns written to
+// the data file it will bump the file version, and that version needs an
+// entry here before it is trusted.
+var compatibilityTable = map[int]VersionSupport{
+	SupportedVersion: {
+		Version: SupportedVersion,
+		ColumnFixups: []ColumnFixup{
+			// The VEHICLE header names three tank columns which are not
+			// written in the data row, per Darren Stone 2024-12-09 via
+			// email.
+			{Section: "VEHICLE", Columns: []string{"Tank 1 Type", "Tank 2 Type", "Tank 2 Units"}},
+		},
+	},
+}
+
+// Compatibility returns the [VersionSupport] entry for a data file version
+// and whether the version is known to this package.
+func Compatibility(version int) (VersionSupport, bool) {
+	support, ok := compatibilityTable[version]
+
+	return support, ok
+}
+
+// SupportedVersions returns every data file version present in the
+// compatibility table in ascending order.
+func SupportedVersions() []int {
+	versions := make([]int, 0, len(compatibilityTable))
+	for version := range compatibilityTable {
+		versions = append(versions, version)
+	}
+
+	slices.Sort(versions)
+
+	return versions
+}
+
+// columnFixups returns the [ColumnFixup] entries for a section of a data file
+// version. The fix-ups of every known version are returned for a version
+// which is not in the compatibility table, since each is only applied when
+// it reconciles the column counts.
+func columnFixups(version int, section string) []ColumnFixup {
+	var fixups []ColumnFixup
+
+	for _, v := range SupportedVersions() {
+		if _, known := compatibilityTable[version]; known && v != version {
+			continue
+		}
+
+		for _, fixup := range compatibilityTable[v].ColumnFixups {
+			if fixup.Section == section {
+				fixups = append(fixups, fixup)
+			}
+		}
+	}
+
+	return fixups
+}
+
+// reconcileColumns returns header with the columns of the first fix-up which
+// makes it agree with a data row of fields values removed. The header is
+// returned unchanged if it already agrees or no fix-up applies. Header
+// columns are compared by their canonical names according to pack.
+func reconcileColumns(header []string, fields int, fixups []ColumnFixup, pack LanguagePack) []string {
+	if len(header) == fields {
+		return header
+	}
+
+	for _, fixup := range fixups {
+		if len(header)-len(fixup.Columns) != fields {
+			continue
+		}
+
+		for i := range len(header) - len(fixup.Columns) + 1 {
+			if columnsMatch(header[i:i+len(fixup.Columns)], fixup.Columns, pack) {
+				return slices.Delete(slices.Clone(header), i, i+len(fixup.Columns))
+			}
+		}
+	}
+
+	return header
+}
+
+// columnsMatch reports whether the written columns have the canonical names
+// given.
+func columnsMatch(written, canonical []string, pack LanguagePack) bool {
+	for i, column := range written {
+		if pack.CanonicalColumnName(column) != canonical[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckCompatibility reports whether a data file with the given Version and
+// Language can be parsed by this package. A language is supported when a
+// [LanguagePack] is registered for it. The returned error wraps
+// [ErrUnsupportedVersion] or [ErrUnsupportedLanguage].
+func CheckCompatibility(version int, language string) error {
+	if _, ok := Compatibility(version); !ok {
+		return fmt.Errorf("%w %d (supported: %v)", ErrUnsupportedVersion, version, SupportedVersions())
+	}
+
+	if _, ok := LookupLanguagePack(language); !ok {
+		return fmt.Errorf("%w '%s' (registered: %v)", ErrUnsupportedLanguage, language, LanguagePackList())
+	}
+
+	return nil
+}
+
+// VersionInfo parses the Version,Language block which follows the file info
+// line at the top of a Road Trip data file.
+func (fileData *RawFileData) VersionInfo() (int, string, error) {
+	delimiters, err := fileData.Delimiters()
+	if err != nil {
+		return 0, "", err
+	}
+
+	// The block runs from the second line of the file up to the first
+	// blank line.
+	_, block, _ := bytes.Cut(*fileData, []byte("\n"))
+	block, _, _ = bytes.Cut(bytes.ReplaceAll(block, []byte("\r\n"), []byte("\n")), []byte("\n\n"))
+
+	reader := csv.NewReader(bytes.NewReader(block))
+	reader.Comma = delimiters.Field()
+
+	records, err := reader.ReadAll()
+	if err != nil || len(records) != 2 {
+		return 0, "", ErrMissingVersion
+	}
+
+	header, values := records[0], records[1]
+
+	versionIndex := versionBlockIndex(header, "Version")
+	languageIndex := versionBlockIndex(header, "Language")
+
+	if versionIndex < 0 || languageIndex < 0 || len(values) != len(header) {
+		return 0, "", ErrMissingVersion
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(values[versionIndex]))
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid Road Trip data file version '%s': %w", values[versionIndex], err)
+	}
+
+	return version, strings.TrimSpace(values[languageIndex]), nil
+}
+
+// versionBlockIndex returns the index of the column of the file info block
+// header named canonical, either in English or as translated by any
+// registered [LanguagePack], or -1 if there is none.
+func versionBlockIndex(header []string, canonical string) int {
+	packs := LanguagePackList()
+
+	return slices.IndexFunc(header, func(name string) bool {
+		name = strings.TrimSpace(name)
+
+		for _, language := range packs {
+			if pack, _ := LookupLanguagePack(language); pack.CanonicalColumnName(name) == canonical {
+				return true
+			}
+		}
+
+		return false
+	})
+}
@@ -0,0 +1,161 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// vinLength is the length of a modern Vehicle Identification Number.
+	vinLength = 17
+
+	// vinCheckDigit, vinYearCycle and vinModelYear are the zero-based
+	// positions of the check digit, the seventh character which
+	// distinguishes the model year cycles, and the model year code.
+	vinCheckDigit = 8
+	vinYearCycle  = 6
+	vinModelYear  = 9
+
+	// modelYearCodes are the model year codes of a 30 year cycle starting
+	// in 1980 and again in 2010.
+	modelYearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+)
+
+var (
+	// ErrInvalidVIN is returned when a Vehicle Identification Number is not
+	// 17 valid characters.
+	ErrInvalidVIN = errors.New("invalid VIN")
+
+	// ErrVINCheckDigit is returned when the check digit of a Vehicle
+	// Identification Number does not match the rest of the number.
+	ErrVINCheckDigit = errors.New("VIN check digit mismatch")
+)
+
+// vinWeights are the position weights of the check digit calculation.
+var vinWeights = [vinLength]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// A VIN is a 17 character Vehicle Identification Number as defined by ISO 3779.
+type VIN string
+
+// ParseVIN returns the [VIN] written in s, with surrounding space and any
+// separating spaces or dashes removed. It does not validate the number.
+func ParseVIN(s string) VIN {
+	return VIN(strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(s))))
+}
+
+// Validate reports whether the VIN is 17 valid characters with a correct
+// check digit. The check digit is mandatory for vehicles sold in North
+// America, so numbers from other regions may fail only with
+// [ErrVINCheckDigit].
+func (v VIN) Validate() error {
+	if len(v) != vinLength {
+		return fmt.Errorf("%w '%s': length %d", ErrInvalidVIN, v, len(v))
+	}
+
+	sum := 0
+
+	for i := range vinLength {
+		value, ok := vinValue(v[i])
+		if !ok {
+			return fmt.Errorf("%w '%s': character '%c'", ErrInvalidVIN, v, v[i])
+		}
+
+		sum += value * vinWeights[i]
+	}
+
+	check := byte('0' + sum%11) //nolint:mnd // the check digit is modulo 11
+	if check == '0'+10 {
+		check = 'X'
+	}
+
+	if v[vinCheckDigit] != check {
+		return fmt.Errorf("%w '%s': want '%c'", ErrVINCheckDigit, v, check)
+	}
+
+	return nil
+}
+
+// vinValue returns the check digit value of a VIN character. The letters I,
+// O and Q are never used.
+func vinValue(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'A' && c <= 'H':
+		return int(c-'A') + 1, true
+	case c >= 'J' && c <= 'N':
+		return int(c-'J') + 1, true
+	case c == 'P':
+		return 7, true //nolint:mnd // ISO 3779 transliteration
+	case c == 'R':
+		return 9, true //nolint:mnd // ISO 3779 transliteration
+	case c >= 'S' && c <= 'Z':
+		return int(c-'S') + 2, true //nolint:mnd // ISO 3779 transliteration
+	default:
+		return 0, false
+	}
+}
+
+// WMI returns the World Manufacturer Identifier, the first three characters
+// of the VIN.
+func (v VIN) WMI() string {
+	if len(v) < 3 { //nolint:mnd // the WMI is three characters
+		return ""
+	}
+
+	return string(v[:3])
+}
+
+// Region returns the continent the vehicle was manufactured for, decoded
+// from the first character of the VIN.
+func (v VIN) Region() string {
+	if len(v) == 0 {
+		return ""
+	}
+
+	switch c := v[0]; {
+	case c >= 'A' && c <= 'H':
+		return "Africa"
+	case c >= 'J' && c <= 'R':
+		return "Asia"
+	case c >= 'S' && c <= 'Z':
+		return "Europe"
+	case c >= '1' && c <= '5':
+		return "North America"
+	case c == '6' || c == '7':
+		return "Oceania"
+	case c == '8' || c == '9':
+		return "South America"
+	default:
+		return ""
+	}
+}
+
+// ModelYear returns the model year encoded in the tenth character of a valid
+// VIN. The year codes repeat every 30 years, and the cycle is chosen by the
+// seventh character as it is for North American passenger vehicles: a digit
+// for 1980 to 2009 and a letter for 2010 to 2039.
+func (v VIN) ModelYear() (int, error) {
+	if err := v.Validate(); err != nil && !errors.Is(err, ErrVINCheckDigit) {
+		return 0, err
+	}
+
+	index := strings.IndexByte(modelYearCodes, v[vinModelYear])
+	if index < 0 {
+		return 0, fmt.Errorf("%w '%s': model year '%c'", ErrInvalidVIN, v, v[vinModelYear])
+	}
+
+	year := 1980 + index //nolint:mnd // the first model year cycle
+
+	if c := v[vinYearCycle]; c < '0' || c > '9' {
+		year += len(modelYearCodes)
+	}
+
+	return year, nil
+}
+
+// String returns the VIN.
+func (v VIN) String() string {
+	return string(v)
+}
@@ -0,0 +1,143 @@
+package roadtrip
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A VolumeUnit identifies the unit in which a [Volume] of fuel is measured.
+type VolumeUnit string
+
+const (
+	// USGallons is the US liquid gallon, written by Road Trip as "gal".
+	USGallons VolumeUnit = "gal"
+
+	// ImperialGallons is the imperial gallon, written by Road Trip as
+	// "imp gal".
+	ImperialGallons VolumeUnit = "imp gal"
+
+	// Liters is the litre, written by Road Trip as "L".
+	Liters VolumeUnit = "L"
+
+	// KilowattHours measures the energy delivered to an electric vehicle.
+	KilowattHours VolumeUnit = "kWh"
+
+	// litersPerUSGallon and litersPerImperialGallon are the exact legal
+	// definitions of each gallon.
+	litersPerUSGallon       = 3.785411784
+	litersPerImperialGallon = 4.54609
+)
+
+var (
+	// ErrUnknownVolumeUnit is returned when a volume unit is not recognized.
+	ErrUnknownVolumeUnit = errors.New("unknown volume unit")
+
+	// ErrIncompatibleUnits is returned when converting between a liquid
+	// volume and electrical energy, or between unknown units.
+	ErrIncompatibleUnits = errors.New("incompatible units")
+)
+
+// ParseVolumeUnit returns the [VolumeUnit] for a unit as written in a Fill
+// Units or Tank Units column, such as "Gal", "L" or "kWh".
+func ParseVolumeUnit(s string) (VolumeUnit, error) {
+	switch strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), ".")) {
+	case "gal", "gallon", "gallons", "us gal", "gal (us)":
+		return USGallons, nil
+	case "imp gal", "imp", "imperial gallon", "imperial gallons", "gal (uk)", "uk gal":
+		return ImperialGallons, nil
+	case "l", "liter", "liters", "litre", "litres":
+		return Liters, nil
+	case "kwh", "kw h", "kw·h", "kw-h", "kilowatt hour", "kilowatt hours", "kilowatt-hour", "kilowatt-hours":
+		return KilowattHours, nil
+	default:
+		return "", fmt.Errorf("%w '%s'", ErrUnknownVolumeUnit, s)
+	}
+}
+
+// IsEnergy reports whether the unit measures electrical energy rather than a
+// liquid volume.
+func (u VolumeUnit) IsEnergy() bool {
+	return u == KilowattHours
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Recognized units are
+// normalized to the constants of this package, and any other value is kept
+// as written.
+func (u *VolumeUnit) UnmarshalCSV(data []byte) error {
+	unit, err := ParseVolumeUnit(string(data))
+	if err != nil {
+		unit = VolumeUnit(data)
+	}
+
+	*u = unit
+
+	return nil
+}
+
+// litersPer returns the number of liters in one unit, or false for energy
+// and unknown units.
+func (u VolumeUnit) litersPer() (float64, bool) {
+	switch u {
+	case USGallons:
+		return litersPerUSGallon, true
+	case ImperialGallons:
+		return litersPerImperialGallon, true
+	case Liters:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+// A Volume is an amount of fuel together with the unit it was recorded in.
+// Electric charges are recorded as a Volume in [KilowattHours].
+type Volume struct {
+	Value float64
+	Unit  VolumeUnit
+}
+
+// In returns the volume converted to unit. Liquid volumes convert between
+// each other, but never to or from [KilowattHours].
+func (v Volume) In(unit VolumeUnit) (Volume, error) {
+	if v.Unit == unit {
+		return v, nil
+	}
+
+	from, fromOK := v.Unit.litersPer()
+	to, toOK := unit.litersPer()
+
+	if !fromOK || !toOK {
+		return Volume{}, fmt.Errorf("%w: cannot convert %s to %s", ErrIncompatibleUnits, v.Unit, unit)
+	}
+
+	return Volume{Value: v.Value * from / to, Unit: unit}, nil
+}
+
+// Add returns the sum of two volumes in the unit of v.
+func (v Volume) Add(o Volume) (Volume, error) {
+	o, err := o.In(v.Unit)
+	if err != nil {
+		return Volume{}, err
+	}
+
+	return Volume{Value: v.Value + o.Value, Unit: v.Unit}, nil
+}
+
+// String returns the volume and its unit, such as "17.743 gal".
+func (v Volume) String() string {
+	return formatQuantity(v.Value, string(v.Unit))
+}
+
+// UnmarshalCSV implements [cvslib.CSVUnmarshaler]. Only the value is read
+// from the cell, the unit is assigned from the matching units column.
+func (v *Volume) UnmarshalCSV(data []byte) error {
+	value, err := parseCSVFloat(data)
+	if err != nil {
+		return err
+	}
+
+	v.Value = value
+
+	return nil
+}